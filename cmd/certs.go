@@ -0,0 +1,316 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+var (
+	certsExpiringWithin string // Flag for the expiry warning window, e.g. "30d" or "72h"
+)
+
+// certsCmd represents the certs command
+var certsCmd = &cobra.Command{
+	Use:   "certs [--expiring-within <duration>]",
+	Short: "Inspect cluster CA and client certificates",
+	Long: `Decode every cluster CA certificate and user client certificate in the
+kubeconfig file, whether embedded or referenced by file, and show the
+subject, issuer, SANs, SHA-256 fingerprint and expiry date of each.
+
+For client certificates, kedit also verifies that the certificate matches
+its private key and that it chains to the CA of every cluster it is used
+with by a context.
+
+--expiring-within accepts a duration such as '30d' or '72h'. If any
+certificate expires within that window (or has already expired), or a
+certificate cannot be read or parsed, the command exits with a non-zero status.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var window time.Duration
+		if certsExpiringWithin != "" {
+			var err error
+			window, err = parseDayDuration(certsExpiringWithin)
+			if err != nil {
+				return fmt.Errorf("invalid value '%s' for --expiring-within: %w", certsExpiringWithin, err)
+			}
+		}
+
+		config, err := loadKubeconfig(resolvedKubeconfigPath)
+		if err != nil {
+			return fmt.Errorf("error loading kubeconfig from '%s': %w", resolvedKubeconfigPath, err)
+		}
+
+		now := time.Now()
+		found := 0
+		expiring := 0
+		unreadable := 0
+		report := func(cert *x509.Certificate) {
+			found++
+			printCertificate(cert, now)
+			if certsExpiringWithin != "" && cert.NotAfter.Before(now.Add(window)) {
+				expiring++
+			}
+		}
+
+		for _, name := range sortedKeys(config.Clusters) {
+			cluster := config.Clusters[name]
+			certs, err := clusterCACertificates(cluster)
+			if err != nil {
+				fmt.Printf("Cluster '%s' CA: %v\n", name, err)
+				unreadable++
+				continue
+			}
+			for _, cert := range certs {
+				fmt.Printf("Cluster '%s' CA:\n", name)
+				report(cert)
+			}
+		}
+
+		for _, name := range sortedKeys(config.AuthInfos) {
+			authInfo := config.AuthInfos[name]
+			certs, err := clientCertificates(authInfo)
+			if err != nil {
+				fmt.Printf("User '%s' client certificate: %v\n", name, err)
+				unreadable++
+				continue
+			}
+			if len(certs) == 0 {
+				continue
+			}
+			fmt.Printf("User '%s' client certificate:\n", name)
+			report(certs[0])
+
+			if err := verifyClientKeyPair(authInfo); err != nil {
+				fmt.Printf("  Key Pair:    MISMATCH (%v)\n", err)
+			} else {
+				fmt.Printf("  Key Pair:    ok\n")
+			}
+
+			for _, clusterName := range clustersUsedByUser(config, name) {
+				cluster, ok := config.Clusters[clusterName]
+				if !ok {
+					continue
+				}
+				caCerts, err := clusterCACertificates(cluster)
+				if err != nil || len(caCerts) == 0 {
+					continue
+				}
+				if err := verifyCertificateChain(certs, caCerts); err != nil {
+					fmt.Printf("  Chain:       FAILED for cluster '%s' (%v)\n", clusterName, err)
+				} else {
+					fmt.Printf("  Chain:       ok for cluster '%s'\n", clusterName)
+				}
+			}
+		}
+
+		if found == 0 && unreadable == 0 {
+			fmt.Printf("No certificates found in '%s'.\n", resolvedKubeconfigPath)
+			return nil
+		}
+
+		// A certificate that cannot be checked must not pass an expiry gate.
+		if certsExpiringWithin == "" || (expiring == 0 && unreadable == 0) {
+			return nil
+		}
+		var problems []string
+		if expiring > 0 {
+			problems = append(problems, fmt.Sprintf("%d certificate(s) expire within %s", expiring, certsExpiringWithin))
+		}
+		if unreadable > 0 {
+			problems = append(problems, fmt.Sprintf("%d certificate(s) could not be read", unreadable))
+		}
+		cmd.SilenceUsage = true
+		return errors.New(strings.Join(problems, "; "))
+	},
+}
+
+// printCertificate prints the details of a certificate, indented under its heading.
+func printCertificate(cert *x509.Certificate, now time.Time) {
+	fmt.Printf("  Subject:     %s\n", cert.Subject.String())
+	fmt.Printf("  Issuer:      %s\n", cert.Issuer.String())
+	if sans := certificateSANs(cert); len(sans) > 0 {
+		fmt.Printf("  SANs:        %s\n", strings.Join(sans, ", "))
+	}
+	fmt.Printf("  Fingerprint: %s\n", certificateFingerprint(cert))
+	fmt.Printf("  Not After:   %s (%s)\n", cert.NotAfter.UTC().Format(time.RFC3339), describeExpiry(cert.NotAfter, now))
+}
+
+// describeExpiry returns a human-readable description of how far away notAfter is.
+func describeExpiry(notAfter, now time.Time) string {
+	days := int(notAfter.Sub(now).Hours() / 24)
+	if notAfter.Before(now) {
+		return fmt.Sprintf("EXPIRED %d day(s) ago", -days)
+	}
+	return fmt.Sprintf("expires in %d day(s)", days)
+}
+
+// certificateSANs returns all subject alternative names of a certificate.
+func certificateSANs(cert *x509.Certificate) []string {
+	var sans []string
+	sans = append(sans, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	for _, uri := range cert.URIs {
+		sans = append(sans, uri.String())
+	}
+	sans = append(sans, cert.EmailAddresses...)
+	return sans
+}
+
+// certificateFingerprint returns the colon-separated SHA-256 fingerprint of a certificate.
+func certificateFingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	parts := make([]string, len(sum))
+	for i, b := range sum {
+		parts[i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(parts, ":")
+}
+
+// clusterCACertificates returns the CA certificates of a cluster, inline or from file.
+func clusterCACertificates(cluster *api.Cluster) ([]*x509.Certificate, error) {
+	data, err := readDataOrFile(cluster.CertificateAuthorityData, cluster.CertificateAuthority, cluster.LocationOfOrigin)
+	if err != nil || len(data) == 0 {
+		return nil, err
+	}
+	return parseCertificates(data)
+}
+
+// clientCertificates returns the client certificate chain of a user, inline or from file.
+func clientCertificates(authInfo *api.AuthInfo) ([]*x509.Certificate, error) {
+	data, err := readDataOrFile(authInfo.ClientCertificateData, authInfo.ClientCertificate, authInfo.LocationOfOrigin)
+	if err != nil || len(data) == 0 {
+		return nil, err
+	}
+	return parseCertificates(data)
+}
+
+// verifyClientKeyPair checks that a user's client certificate matches its private key.
+func verifyClientKeyPair(authInfo *api.AuthInfo) error {
+	certData, err := readDataOrFile(authInfo.ClientCertificateData, authInfo.ClientCertificate, authInfo.LocationOfOrigin)
+	if err != nil {
+		return err
+	}
+	keyData, err := readDataOrFile(authInfo.ClientKeyData, authInfo.ClientKey, authInfo.LocationOfOrigin)
+	if err != nil {
+		return err
+	}
+	if len(keyData) == 0 {
+		return fmt.Errorf("no client key")
+	}
+	_, err = tls.X509KeyPair(certData, keyData)
+	return err
+}
+
+// verifyCertificateChain checks that the leaf of certs chains to one of caCerts
+// for client authentication, using any further certs as intermediates.
+func verifyCertificateChain(certs, caCerts []*x509.Certificate) error {
+	roots := x509.NewCertPool()
+	for _, caCert := range caCerts {
+		roots.AddCert(caCert)
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	return err
+}
+
+// clustersUsedByUser returns the sorted names of the clusters that are paired
+// with the given user by at least one context.
+func clustersUsedByUser(config *api.Config, userName string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, context := range config.Contexts {
+		if context.AuthInfo == userName && context.Cluster != "" && !seen[context.Cluster] {
+			seen[context.Cluster] = true
+			names = append(names, context.Cluster)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// readDataOrFile returns the inline data if set, otherwise the contents of the referenced file.
+func readDataOrFile(data []byte, path, locationOfOrigin string) ([]byte, error) {
+	if len(data) > 0 {
+		return data, nil
+	}
+	if path == "" {
+		return nil, nil
+	}
+	content, err := os.ReadFile(resolveReference(path, locationOfOrigin))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read '%s': %w", path, err)
+	}
+	return content, nil
+}
+
+// parseCertificates decodes all PEM-encoded certificates in data.
+func parseCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no PEM-encoded certificate found")
+	}
+	return certs, nil
+}
+
+// parseDayDuration parses a duration, additionally accepting a whole number of days such as "30d".
+func parseDayDuration(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number of days '%s'", days)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// sortedKeys returns the keys of a map in sorted order.
+func sortedKeys[V any](items map[string]V) []string {
+	names := make([]string, 0, len(items))
+	for name := range items {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	certsCmd.Flags().StringVar(&certsExpiringWithin, "expiring-within", "", "Exit non-zero if any certificate expires within this duration (e.g. 30d)")
+	rootCmd.AddCommand(certsCmd)
+}
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// testCertificate holds a generated certificate with its PEM encodings and private key.
+type testCertificate struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+// newTestCA generates a self-signed CA certificate.
+func newTestCA(t *testing.T, commonName string) *testCertificate {
+	t.Helper()
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(10 * 365 * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	return newTestCertificate(t, template, nil)
+}
+
+// newTestClientCert generates a client certificate signed by ca that expires at notAfter.
+func newTestClientCert(t *testing.T, ca *testCertificate, commonName string, notAfter time.Time) *testCertificate {
+	t.Helper()
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: commonName, Organization: []string{"system:masters"}},
+		NotBefore:   notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:    notAfter,
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	return newTestCertificate(t, template, ca)
+}
+
+// newTestCertificate signs template with parent, or self-signs it if parent is nil.
+func newTestCertificate(t *testing.T, template *x509.Certificate, parent *testCertificate) *testCertificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	assert.NoError(t, err)
+	template.SerialNumber = serial
+
+	parentCert, parentKey := template, key
+	if parent != nil {
+		parentCert, parentKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parentCert, &key.PublicKey, parentKey)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	return &testCertificate{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func TestCertsCommand(t *testing.T) {
+	ca := newTestCA(t, "test-ca")
+
+	// Helper to create a kubeconfig with one cluster and a user holding the given client certificate.
+	createKubeconfigForCerts := func(tempDir string, client *testCertificate, keyPEM []byte) string {
+		config := api.NewConfig()
+		config.CurrentContext = "context1"
+		config.Clusters["cluster1"] = &api.Cluster{Server: "https://cluster1", CertificateAuthorityData: ca.certPEM}
+		config.AuthInfos["user1"] = &api.AuthInfo{ClientCertificateData: client.certPEM, ClientKeyData: keyPEM}
+		config.Contexts["context1"] = &api.Context{Cluster: "cluster1", AuthInfo: "user1"}
+
+		kubeconfigPath := filepath.Join(tempDir, "config")
+		assert.NoError(t, saveKubeconfig(config, kubeconfigPath))
+		return kubeconfigPath
+	}
+
+	// Test inspecting valid certificates.
+	t.Run("certs valid", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-certs-valid-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		client := newTestClientCert(t, ca, "admin", time.Now().Add(200*24*time.Hour))
+		kubeconfigPath := createKubeconfigForCerts(tempDir, client, client.keyPEM)
+
+		output := executeCommandC(t, "certs", "--expiring-within", "30d", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Cluster 'cluster1' CA:\n  Subject:     CN=test-ca\n  Issuer:      CN=test-ca")
+		assert.Contains(t, output, "User 'user1' client certificate:\n  Subject:     CN=admin,O=system:masters")
+		assert.Contains(t, output, "  Fingerprint: "+certificateFingerprint(client.cert))
+		assert.Contains(t, output, "(expires in 199 day(s))")
+		assert.Contains(t, output, "  Key Pair:    ok")
+		assert.Contains(t, output, "  Chain:       ok for cluster 'cluster1'")
+		assert.NotContains(t, output, "Error:")
+	})
+
+	// Test that certificates expiring soon produce an error.
+	t.Run("certs expiring within", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-certs-expiring-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		client := newTestClientCert(t, ca, "admin", time.Now().Add(10*24*time.Hour))
+		kubeconfigPath := createKubeconfigForCerts(tempDir, client, client.keyPEM)
+
+		output := executeCommandC(t, "certs", "--expiring-within", "30d", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Error: 1 certificate(s) expire within 30d")
+		assert.NotContains(t, output, "Usage:")
+	})
+
+	// Test that unreadable certificates fail the expiry check.
+	t.Run("certs unreadable", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-certs-unreadable-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		client := newTestClientCert(t, ca, "admin", time.Now().Add(200*24*time.Hour))
+		kubeconfigPath := createKubeconfigForCerts(tempDir, client, client.keyPEM)
+		config, err := loadKubeconfig(kubeconfigPath)
+		assert.NoError(t, err)
+		config.AuthInfos["user2"] = &api.AuthInfo{ClientCertificate: filepath.Join(tempDir, "missing.crt")}
+		config.AuthInfos["user3"] = &api.AuthInfo{ClientCertificateData: []byte("not a certificate")}
+		assert.NoError(t, saveKubeconfig(config, kubeconfigPath))
+
+		output := executeCommandC(t, "certs", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "User 'user2' client certificate: failed to read")
+		assert.NotContains(t, output, "Error:")
+
+		output = executeCommandC(t, "certs", "--expiring-within", "30d", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Error: 2 certificate(s) could not be read")
+		assert.NotContains(t, output, "Usage:")
+	})
+
+	// Test detection of a mismatched key and a foreign CA.
+	t.Run("certs key mismatch and broken chain", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-certs-mismatch-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		otherCA := newTestCA(t, "other-ca")
+		client := newTestClientCert(t, otherCA, "admin", time.Now().Add(200*24*time.Hour))
+		kubeconfigPath := createKubeconfigForCerts(tempDir, client, otherCA.keyPEM)
+
+		output := executeCommandC(t, "certs", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "  Key Pair:    MISMATCH")
+		assert.Contains(t, output, "  Chain:       FAILED for cluster 'cluster1'")
+	})
+
+	// Test a kubeconfig without certificates.
+	t.Run("certs none", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-certs-none-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		kubeconfigPath := filepath.Join(tempDir, "config")
+
+		output := executeCommandC(t, "certs", "--kubeconfig", kubeconfigPath)
+		assert.Equal(t, "No certificates found in '"+kubeconfigPath+"'.", output)
+	})
+}
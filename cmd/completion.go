@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// itemTypes are the item types accepted as first argument by delete and rename.
+var itemTypes = []string{"cluster", "user", "context"}
+
+// completeFromKubeconfig returns the names of itemType in the kubeconfig at path
+// that start with toComplete and are not in exclude.
+// Encrypted kubeconfigs are only completed if $KEDIT_PASSPHRASE is set, as
+// completion runs without a terminal to ask on.
+func completeFromKubeconfig(path, itemType, toComplete string, exclude []string) []string {
+	config, err := loadKubeconfig(path)
+	if err != nil {
+		return nil
+	}
+	return completeNames(config, itemType, toComplete, exclude)
+}
+
+// completeNames returns the names of itemType in config that start with
+// toComplete and are not in exclude.
+func completeNames(config *api.Config, itemType, toComplete string, exclude []string) []string {
+	var names []string
+	switch itemType {
+	case "cluster":
+		names = sortedKeys(config.Clusters)
+	case "user":
+		names = sortedKeys(config.AuthInfos)
+	case "context":
+		names = sortedKeys(config.Contexts)
+	}
+
+	var completions []string
+	for _, name := range names {
+		if strings.HasPrefix(name, toComplete) && !containsString(exclude, name) {
+			completions = append(completions, name)
+		}
+	}
+	return completions
+}
+
+// completeWords returns the words that start with toComplete.
+func completeWords(words []string, toComplete string) []string {
+	var completions []string
+	for _, word := range words {
+		if strings.HasPrefix(word, toComplete) {
+			completions = append(completions, word)
+		}
+	}
+	return completions
+}
+
+// containsString reports whether s is in list.
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// completeTargetNames completes names of itemType from the target kubeconfig.
+// PersistentPreRunE does not run during completion, so the path is resolved here.
+func completeTargetNames(itemType, toComplete string, exclude []string) ([]string, cobra.ShellCompDirective) {
+	path, err := resolveKubeconfigPath()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return completeFromKubeconfig(path, itemType, toComplete, exclude), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTypeThenName completes the first argument from types and the second
+// with the names of the chosen type, as used by "delete cluster <name>".
+func completeTypeThenName(types []string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		switch len(args) {
+		case 0:
+			return completeWords(types, toComplete), cobra.ShellCompDirectiveNoFileComp
+		case 1:
+			return completeTargetNames(args[0], toComplete, nil)
+		default:
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+	}
+}
+
+// completeName completes a single argument with the names of itemType.
+func completeName(itemType string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) != 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completeTargetNames(itemType, toComplete, nil)
+	}
+}
+
+// completeContextNames completes any number of context names, skipping those already given.
+func completeContextNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return completeTargetNames("context", toComplete, args)
+}
+
+// completeSourceContexts completes the context to merge from the file given with --from.
+func completeSourceContexts(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 || sourceKubeconfigPath == "" {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	path, err := homedir.Expand(sourceKubeconfigPath)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return completeFromKubeconfig(path, "context", toComplete, nil), cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompletion(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "kedit-test-completion-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	kubeconfigPath := filepath.Join(tempDir, "config")
+	err = ioutil.WriteFile(kubeconfigPath, []byte(`
+apiVersion: v1
+clusters:
+- cluster:
+    server: https://cluster1
+  name: cluster1
+- cluster:
+    server: https://other
+  name: other-cluster
+contexts:
+- context:
+    cluster: cluster1
+    user: user1
+  name: context1
+- context:
+    cluster: cluster1
+    user: user1
+  name: context2
+current-context: context1
+kind: Config
+preferences: {}
+users:
+- name: user1
+  user:
+    token: token1
+`), 0644)
+	assert.NoError(t, err)
+
+	sourcePath := filepath.Join(tempDir, "source")
+	err = ioutil.WriteFile(sourcePath, []byte(`
+apiVersion: v1
+clusters: []
+contexts:
+- context:
+    cluster: remote
+  name: remote-context
+kind: Config
+users: []
+`), 0644)
+	assert.NoError(t, err)
+
+	// completions runs the hidden __complete command and returns the suggested words.
+	completions := func(args ...string) []string {
+		output := executeCommandC(t, append([]string{"__complete"}, args...)...)
+		var words []string
+		for _, line := range strings.Split(output, "\n") {
+			if line == "" || strings.HasPrefix(line, ":") || strings.HasPrefix(line, "Completion ended") {
+				continue
+			}
+			words = append(words, line)
+		}
+		return words
+	}
+
+	assert.Equal(t, []string{"cluster", "user", "context"}, completions("delete", "--kubeconfig", kubeconfigPath, ""))
+	assert.Equal(t, []string{"cluster", "user", "context", "all"}, completions("list", ""))
+	assert.Equal(t, []string{"context1", "context2"}, completions("delete", "context", "--kubeconfig", kubeconfigPath, ""))
+	assert.Equal(t, []string{"other-cluster"}, completions("rename", "cluster", "--kubeconfig", kubeconfigPath, "o"))
+	assert.Empty(t, completions("rename", "cluster", "cluster1", "--kubeconfig", kubeconfigPath, ""))
+	assert.Equal(t, []string{"context2"}, completions("extract", "context1", "--kubeconfig", kubeconfigPath, ""))
+	assert.Equal(t, []string{"remote-context"}, completions("merge", "--from", sourcePath, ""))
+	assert.Equal(t, []string{"user1"}, completions("set", "context", "context3", "--kubeconfig", kubeconfigPath, "--user", ""))
+
+	// Encrypted kubeconfigs are completed when the passphrase is in the environment.
+	t.Setenv("KEDIT_PASSPHRASE", "completion passphrase")
+	content, err := ioutil.ReadFile(sourcePath)
+	assert.NoError(t, err)
+	encrypted, err := encryptKubeconfig(content, []byte("completion passphrase"))
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(sourcePath, encrypted, 0600))
+	assert.Equal(t, []string{"remote-context"}, completions("merge", "--from", sourcePath, ""))
+}
@@ -1,9 +1,11 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd/api"
 )
 
 // deleteCmd represents the delete command
@@ -21,40 +23,36 @@ Valid types are:
   context    Delete a context.
 
 Warning: Deleting a cluster or user that is currently referenced by one
-or more contexts may break those contexts. kedit removes the specified item directly.`,
-	Args: cobra.ExactArgs(2), // Requires type and name
+or more contexts may break those contexts. kedit removes the specified item directly.
+
+On a terminal, the name can be omitted to pick it with a fuzzy search.`,
+	Args:              exactArgsOrPick(2, 1), // Requires type and name; the name can be picked on a terminal
+	ValidArgsFunction: completeTypeThenName(itemTypes),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		itemType := args[0] // Will be "cluster", "user", or "context"
-		itemName := args[1]
 
 		config, err := loadKubeconfig(resolvedKubeconfigPath)
 		if err != nil {
 			return fmt.Errorf("error loading kubeconfig from '%s': %w", resolvedKubeconfigPath, err)
 		}
 
-		itemExisted := false
-		switch itemType {
-		case "cluster":
-			if _, ok := config.Clusters[itemName]; ok {
-				delete(config.Clusters, itemName)
-				itemExisted = true
-			}
-		case "user":
-			if _, ok := config.AuthInfos[itemName]; ok {
-				delete(config.AuthInfos, itemName)
-				itemExisted = true
+		var itemName string
+		if len(args) == 2 {
+			itemName = args[1]
+		} else {
+			itemName, err = pickName(config, itemType, resolvedKubeconfigPath)
+			if errors.Is(err, errPickerCancelled) {
+				fmt.Printf("No %s selected. Nothing to delete.\n", itemType)
+				return nil
 			}
-		case "context":
-			if _, ok := config.Contexts[itemName]; ok {
-				delete(config.Contexts, itemName)
-				itemExisted = true
-				if config.CurrentContext == itemName {
-					config.CurrentContext = ""
-				}
+			if err != nil {
+				return err
 			}
-		default:
-			// This case should ideally not be reached.
-			return fmt.Errorf("invalid type '%s'. Must be one of: cluster, user, context", itemType)
+		}
+
+		itemExisted, err := deleteItem(config, itemType, itemName)
+		if err != nil {
+			return err
 		}
 
 		if !itemExisted {
@@ -71,6 +69,48 @@ or more contexts may break those contexts. kedit removes the specified item dire
 	},
 }
 
+// deleteItem removes a cluster, user or context from the config, clearing the
+// current-context if it is the deleted context. It reports whether the item existed.
+func deleteItem(config *api.Config, itemType, itemName string) (bool, error) {
+	switch itemType {
+	case "cluster":
+		if _, ok := config.Clusters[itemName]; ok {
+			delete(config.Clusters, itemName)
+			return true, nil
+		}
+	case "user":
+		if _, ok := config.AuthInfos[itemName]; ok {
+			delete(config.AuthInfos, itemName)
+			return true, nil
+		}
+	case "context":
+		if _, ok := config.Contexts[itemName]; ok {
+			delete(config.Contexts, itemName)
+			if config.CurrentContext == itemName {
+				config.CurrentContext = ""
+			}
+			return true, nil
+		}
+	default:
+		// This case should ideally not be reached.
+		return false, fmt.Errorf("invalid type '%s'. Must be one of: cluster, user, context", itemType)
+	}
+	return false, nil
+}
+
+// contextsReferencing returns the sorted names of the contexts that reference
+// the given cluster or user.
+func contextsReferencing(config *api.Config, itemType, itemName string) []string {
+	var names []string
+	for _, name := range sortedKeys(config.Contexts) {
+		context := config.Contexts[name]
+		if (itemType == "cluster" && context.Cluster == itemName) || (itemType == "user" && context.AuthInfo == itemName) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
 func init() {
 	rootCmd.AddCommand(deleteCmd)
 }
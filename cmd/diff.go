@@ -0,0 +1,308 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+var (
+	diffMatchRenames bool // Flag to match removed and added entries with identical content as renames
+	diffExitCode     bool // Flag to exit with code 1 when there are differences
+)
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff <old_kubeconfig> <new_kubeconfig> [--match-renames] [--exit-code]",
+	Short: "Compare two kubeconfig files semantically",
+	Long: `Compare the clusters, users and contexts of two kubeconfig files by name
+and content, regardless of the order of entries in the files.
+
+Entries are reported as added (+), removed (-), changed (~) or, with
+--match-renames, renamed (>). Changes are shown per field. Secrets (tokens,
+passwords, keys, certificate data, auth-provider settings and exec environment
+values) are never printed: they are shown as a short SHA-256 hash instead, so
+you can still see whether they changed.
+
+With --match-renames, a removed and an added entry with identical content are
+reported as a rename, and contexts are compared as if their references to
+renamed clusters and users had been updated.
+
+--exit-code makes kedit exit with code 1 if there are differences.`,
+	Args: cobra.ExactArgs(2), // Requires the two kubeconfig files
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configs := make([]*api.Config, len(args))
+		paths := make([]string, len(args))
+		for i, arg := range args {
+			path, err := absolutePath(arg)
+			if err != nil {
+				return err
+			}
+			if _, err := os.Stat(path); err != nil {
+				return fmt.Errorf("failed to access kubeconfig '%s': %w", path, err)
+			}
+			config, err := loadKubeconfig(path)
+			if err != nil {
+				return err
+			}
+			configs[i], paths[i] = config, path
+		}
+
+		lines, err := diffConfigs(configs[0], configs[1], diffMatchRenames)
+		if err != nil {
+			return err
+		}
+		if len(lines) == 0 {
+			fmt.Printf("No differences between '%s' and '%s'.\n", paths[0], paths[1])
+			return nil
+		}
+		for _, line := range lines {
+			fmt.Println(line)
+		}
+		if diffExitCode {
+			cmd.SilenceErrors = true
+			cmd.SilenceUsage = true
+			return &exitCodeError{code: 1}
+		}
+		return nil
+	},
+}
+
+// diffConfigs returns the lines describing the differences from oldConfig to newConfig.
+func diffConfigs(oldConfig, newConfig *api.Config, matchRenames bool) ([]string, error) {
+	var lines []string
+	if oldConfig.CurrentContext != newConfig.CurrentContext {
+		lines = append(lines, fmt.Sprintf("~ current-context: '%s' -> '%s'", oldConfig.CurrentContext, newConfig.CurrentContext))
+	}
+
+	oldClusters, err := entryFields(oldConfig.Clusters)
+	if err != nil {
+		return nil, err
+	}
+	newClusters, err := entryFields(newConfig.Clusters)
+	if err != nil {
+		return nil, err
+	}
+	clusterLines, clusterRenames := diffEntries("cluster", oldClusters, newClusters, matchRenames)
+	lines = append(lines, clusterLines...)
+
+	oldUsers, err := entryFields(oldConfig.AuthInfos)
+	if err != nil {
+		return nil, err
+	}
+	newUsers, err := entryFields(newConfig.AuthInfos)
+	if err != nil {
+		return nil, err
+	}
+	userLines, userRenames := diffEntries("user", oldUsers, newUsers, matchRenames)
+	lines = append(lines, userLines...)
+
+	// Contexts are compared as if their references to renamed clusters and users had been updated.
+	oldContexts := make(map[string]*api.Context, len(oldConfig.Contexts))
+	for name, context := range oldConfig.Contexts {
+		translated := context.DeepCopy()
+		if newName, ok := clusterRenames[translated.Cluster]; ok {
+			translated.Cluster = newName
+		}
+		if newName, ok := userRenames[translated.AuthInfo]; ok {
+			translated.AuthInfo = newName
+		}
+		oldContexts[name] = translated
+	}
+	oldContextFields, err := entryFields(oldContexts)
+	if err != nil {
+		return nil, err
+	}
+	newContextFields, err := entryFields(newConfig.Contexts)
+	if err != nil {
+		return nil, err
+	}
+	contextLines, _ := diffEntries("context", oldContextFields, newContextFields, matchRenames)
+	return append(lines, contextLines...), nil
+}
+
+// diffEntries compares the entries of one kind by name. It returns the lines
+// describing the differences and the renames found, mapping old to new names.
+func diffEntries(kind string, oldEntries, newEntries map[string]map[string]string, matchRenames bool) ([]string, map[string]string) {
+	renames := make(map[string]string)
+	renamedTo := make(map[string]bool)
+	if matchRenames {
+		for _, oldName := range sortedKeys(oldEntries) {
+			if _, ok := newEntries[oldName]; ok {
+				continue
+			}
+			for _, newName := range sortedKeys(newEntries) {
+				if _, ok := oldEntries[newName]; ok || renamedTo[newName] {
+					continue
+				}
+				if reflect.DeepEqual(oldEntries[oldName], newEntries[newName]) {
+					renames[oldName] = newName
+					renamedTo[newName] = true
+					break
+				}
+			}
+		}
+	}
+	names := make(map[string]bool)
+	for name := range oldEntries {
+		names[name] = true
+	}
+	for name := range newEntries {
+		names[name] = true
+	}
+
+	var lines []string
+	for _, name := range sortedKeys(names) {
+		oldFields, inOld := oldEntries[name]
+		newFields, inNew := newEntries[name]
+		switch {
+		case inOld && inNew:
+			if fieldLines := diffFields(oldFields, newFields); len(fieldLines) > 0 {
+				lines = append(lines, fmt.Sprintf("~ %s '%s' changed:", kind, name))
+				lines = append(lines, fieldLines...)
+			}
+		case inOld:
+			if newName, ok := renames[name]; ok {
+				lines = append(lines, fmt.Sprintf("> %s '%s' renamed to '%s'", kind, name, newName))
+			} else {
+				lines = append(lines, fmt.Sprintf("- %s '%s' removed", kind, name))
+			}
+		case !renamedTo[name]:
+			lines = append(lines, fmt.Sprintf("+ %s '%s' added", kind, name))
+		}
+	}
+	return lines, renames
+}
+
+// diffFields returns one line per field that differs between two entries.
+func diffFields(oldFields, newFields map[string]string) []string {
+	keys := make(map[string]bool)
+	for key := range oldFields {
+		keys[key] = true
+	}
+	for key := range newFields {
+		keys[key] = true
+	}
+
+	var lines []string
+	for _, key := range sortedKeys(keys) {
+		oldValue, inOld := oldFields[key]
+		newValue, inNew := newFields[key]
+		switch {
+		case inOld && inNew:
+			if oldValue == newValue {
+				continue
+			}
+			if isSecretField(key) {
+				lines = append(lines, fmt.Sprintf("    %s: changed (%s -> %s)", key, secretHash(oldValue), secretHash(newValue)))
+			} else {
+				lines = append(lines, fmt.Sprintf("    %s: '%s' -> '%s'", key, oldValue, newValue))
+			}
+		case inOld:
+			lines = append(lines, fmt.Sprintf("    - %s: %s", key, displayFieldValue(key, oldValue)))
+		default:
+			lines = append(lines, fmt.Sprintf("    + %s: %s", key, displayFieldValue(key, newValue)))
+		}
+	}
+	return lines
+}
+
+// entryFields flattens each entry of a kubeconfig map into field paths and values.
+func entryFields[V any](entries map[string]V) (map[string]map[string]string, error) {
+	result := make(map[string]map[string]string, len(entries))
+	for name, entry := range entries {
+		fields, err := contentFields(entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize '%s': %w", name, err)
+		}
+		result[name] = fields
+	}
+	return result, nil
+}
+
+// contentFields flattens a cluster, user or context into field paths and values.
+func contentFields(entry interface{}) (map[string]string, error) {
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err := json.Unmarshal(content, &value); err != nil {
+		return nil, err
+	}
+	fields := make(map[string]string)
+	flattenFields("", value, fields)
+	return fields, nil
+}
+
+// flattenFields stores every scalar in value under its dotted path, e.g. "exec.args[0]".
+// Empty values are skipped, so that an omitted and an empty field compare equal.
+func flattenFields(path string, value interface{}, fields map[string]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			flattenFields(childPath, child, fields)
+		}
+	case []interface{}:
+		for i, child := range v {
+			flattenFields(fmt.Sprintf("%s[%d]", path, i), child, fields)
+		}
+	case nil:
+	case string:
+		if v != "" {
+			fields[path] = v
+		}
+	case bool:
+		if v {
+			fields[path] = "true"
+		}
+	default:
+		fields[path] = fmt.Sprint(v)
+	}
+}
+
+// isSecretField reports whether the value of the field at path must not be printed.
+func isSecretField(path string) bool {
+	switch {
+	case path == "token", path == "password":
+		return true
+	case strings.HasSuffix(path, "-data"):
+		return true
+	case strings.HasPrefix(path, "auth-provider.config."):
+		return true
+	case strings.HasPrefix(path, "exec.env[") && strings.HasSuffix(path, "].value"):
+		return true
+	}
+	return false
+}
+
+// displayFieldValue returns the value to print for a field, hashing secrets.
+func displayFieldValue(path, value string) string {
+	if isSecretField(path) {
+		return secretHash(value)
+	}
+	return "'" + value + "'"
+}
+
+// secretHash returns a short SHA-256 hash identifying a secret without revealing it.
+func secretHash(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return "sha256:" + hex.EncodeToString(sum[:])[:12]
+}
+
+func init() {
+	diffCmd.Flags().BoolVar(&diffMatchRenames, "match-renames", false, "Report removed and added entries with identical content as renames")
+	diffCmd.Flags().BoolVar(&diffExitCode, "exit-code", false, "Exit with code 1 if there are differences")
+	rootCmd.AddCommand(diffCmd)
+}
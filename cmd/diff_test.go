@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffCommand(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "kedit-test-diff-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	oldPath := filepath.Join(tempDir, "old")
+	err = ioutil.WriteFile(oldPath, []byte(`
+apiVersion: v1
+clusters:
+- cluster:
+    server: https://cluster1
+  name: cluster1
+- cluster:
+    server: https://legacy
+  name: legacy
+contexts:
+- context:
+    cluster: cluster1
+    user: user1
+  name: context1
+current-context: context1
+kind: Config
+preferences: {}
+users:
+- name: user1
+  user:
+    token: old-secret-token
+`), 0644)
+	assert.NoError(t, err)
+
+	// The same entries in a different order, with a changed server and token,
+	// a renamed user and an added context.
+	newPath := filepath.Join(tempDir, "new")
+	err = ioutil.WriteFile(newPath, []byte(`
+apiVersion: v1
+kind: Config
+current-context: context1
+users:
+- name: admin
+  user:
+    token: new-secret-token
+contexts:
+- context:
+    cluster: legacy
+    user: admin
+    namespace: tools
+  name: context2
+- context:
+    cluster: cluster1
+    user: admin
+  name: context1
+clusters:
+- cluster:
+    server: https://legacy
+  name: legacy
+- cluster:
+    server: https://cluster1.example.com
+  name: cluster1
+`), 0644)
+	assert.NoError(t, err)
+
+	// Test a plain comparison by name.
+	t.Run("diff by name", func(t *testing.T) {
+		output := executeCommandC(t, "diff", oldPath, newPath)
+		expectedOutput := "~ cluster 'cluster1' changed:\n" +
+			"    server: 'https://cluster1' -> 'https://cluster1.example.com'\n" +
+			"+ user 'admin' added\n" +
+			"- user 'user1' removed\n" +
+			"~ context 'context1' changed:\n" +
+			"    user: 'user1' -> 'admin'\n" +
+			"+ context 'context2' added"
+		assert.Equal(t, expectedOutput, output)
+		assert.NotContains(t, output, "secret-token")
+	})
+
+	// Test that identical content under a new name is reported as a rename.
+	t.Run("diff match renames", func(t *testing.T) {
+		renamedPath := filepath.Join(tempDir, "renamed")
+		err := ioutil.WriteFile(renamedPath, []byte(`
+apiVersion: v1
+kind: Config
+current-context: context1
+users:
+- name: admin
+  user:
+    token: old-secret-token
+contexts:
+- context:
+    cluster: cluster1
+    user: admin
+  name: context1
+clusters:
+- cluster:
+    server: https://cluster1
+  name: cluster1
+`), 0644)
+		assert.NoError(t, err)
+
+		output := executeCommandC(t, "diff", oldPath, renamedPath, "--match-renames")
+		expectedOutput := "- cluster 'legacy' removed\n" +
+			"> user 'user1' renamed to 'admin'"
+		assert.Equal(t, expectedOutput, output)
+	})
+
+	// Test that secrets are shown as hashes and identical files report no differences.
+	t.Run("diff secrets and no differences", func(t *testing.T) {
+		lines := diffFields(map[string]string{"token": "a", "username": "bob"}, map[string]string{"token": "b", "password": "p"})
+		assert.Equal(t, []string{
+			"    + password: " + secretHash("p"),
+			"    token: changed (" + secretHash("a") + " -> " + secretHash("b") + ")",
+			"    - username: 'bob'",
+		}, lines)
+
+		output := executeCommandC(t, "diff", oldPath, oldPath, "--exit-code")
+		assert.Equal(t, "No differences between '"+oldPath+"' and '"+oldPath+"'.", output)
+
+		output = executeCommandC(t, "diff", oldPath, filepath.Join(tempDir, "missing"))
+		assert.Contains(t, output, "Error: failed to access kubeconfig '"+filepath.Join(tempDir, "missing")+"'")
+	})
+}
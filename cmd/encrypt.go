@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	// kubeconfigPassphraseEnv is the environment variable holding the passphrase of encrypted kubeconfigs.
+	kubeconfigPassphraseEnv = "KEDIT_PASSPHRASE"
+	// encryptedKubeconfigType is the PEM block type of encrypted kubeconfigs.
+	encryptedKubeconfigType = "KEDIT ENCRYPTED KUBECONFIG"
+	// encryptedKubeconfigPurpose is authenticated along with the kubeconfig.
+	encryptedKubeconfigPurpose = "kedit encrypted kubeconfig"
+)
+
+var (
+	encryptOutput string // Flag for the file to write the encrypted kubeconfig to
+	decryptOutput string // Flag for the file to write the decrypted kubeconfig to
+)
+
+// kubeconfigPassphrases remembers the passphrase of each encrypted kubeconfig
+// opened by this process, so that it is asked for at most once.
+var kubeconfigPassphrases = make(map[string][]byte)
+
+// encryptCmd represents the encrypt command
+var encryptCmd = &cobra.Command{
+	Use:   "encrypt <kubeconfig> [-o <file>|-]",
+	Short: "Encrypt a kubeconfig file with a passphrase",
+	Long: `Encrypt a kubeconfig file with a key derived from a passphrase (scrypt and
+AES-256-GCM), so that backups and copies on shared drives are never plaintext.
+
+The file is encrypted in place unless --output (or -o) gives another file;
+'-' writes to stdout. The passphrase is read from $KEDIT_PASSPHRASE or asked
+for on the terminal.
+
+kedit commands read and write encrypted kubeconfigs transparently, keeping them
+encrypted. kubectl cannot read them; use 'kedit decrypt' or 'kedit run'.`,
+	Args: cobra.ExactArgs(1), // Requires the kubeconfig
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := absolutePath(args[0])
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read kubeconfig '%s': %w", path, err)
+		}
+		if isEncryptedKubeconfig(content) {
+			return fmt.Errorf("'%s' is already encrypted", path)
+		}
+		if _, err := clientcmd.Load(content); err != nil {
+			return fmt.Errorf("'%s' is not a valid kubeconfig: %w", path, err)
+		}
+		passphrase, err := readPassphrase(kubeconfigPassphraseEnv, "Passphrase", true)
+		if err != nil {
+			return err
+		}
+		encrypted, err := encryptKubeconfig(content, passphrase)
+		if err != nil {
+			return err
+		}
+		return writeConvertedKubeconfig(encrypted, path, encryptOutput, "Encrypted")
+	},
+}
+
+// decryptCmd represents the decrypt command
+var decryptCmd = &cobra.Command{
+	Use:   "decrypt <kubeconfig> [-o <file>|-]",
+	Short: "Decrypt a kubeconfig file encrypted with 'kedit encrypt'",
+	Long: `Decrypt a kubeconfig file encrypted with 'kedit encrypt'.
+
+The file is decrypted in place unless --output (or -o) gives another file;
+'-' writes to stdout. The passphrase is read from $KEDIT_PASSPHRASE or asked
+for on the terminal.`,
+	Args: cobra.ExactArgs(1), // Requires the kubeconfig
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := absolutePath(args[0])
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read kubeconfig '%s': %w", path, err)
+		}
+		if !isEncryptedKubeconfig(content) {
+			return fmt.Errorf("'%s' is not encrypted", path)
+		}
+		plaintext, _, err := openEncryptedKubeconfig(path, content)
+		if err != nil {
+			return err
+		}
+		return writeConvertedKubeconfig(plaintext, path, decryptOutput, "Decrypted")
+	},
+}
+
+// writeConvertedKubeconfig writes content to output, or back to path if output is empty.
+func writeConvertedKubeconfig(content []byte, path, output, verb string) error {
+	if output == "-" {
+		if _, err := os.Stdout.Write(content); err != nil {
+			return fmt.Errorf("failed to write to stdout: %w", err)
+		}
+		return nil
+	}
+	target := path
+	if output != "" {
+		var err error
+		if target, err = absolutePath(output); err != nil {
+			return err
+		}
+	}
+	if err := os.WriteFile(target, content, 0600); err != nil {
+		return fmt.Errorf("failed to write '%s': %w", target, err)
+	}
+	fmt.Printf("%s '%s' to '%s'.\n", verb, path, target)
+	return nil
+}
+
+// isEncryptedKubeconfig reports whether content was written by encryptKubeconfig.
+func isEncryptedKubeconfig(content []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(content), []byte("-----BEGIN "+encryptedKubeconfigType+"-----"))
+}
+
+// encryptKubeconfig encrypts a kubeconfig into a PEM block holding the envelope.
+func encryptKubeconfig(plaintext, passphrase []byte) ([]byte, error) {
+	envelope, err := sealWithPassphrase(plaintext, passphrase, encryptedKubeconfigPurpose)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize encrypted kubeconfig: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: encryptedKubeconfigType, Bytes: data}), nil
+}
+
+// openEncryptedKubeconfig decrypts the content of the encrypted kubeconfig at
+// path, and returns the plaintext and the passphrase that opened it.
+func openEncryptedKubeconfig(path string, content []byte) ([]byte, []byte, error) {
+	block, _ := pem.Decode(content)
+	if block == nil || block.Type != encryptedKubeconfigType {
+		return nil, nil, fmt.Errorf("failed to parse encrypted kubeconfig '%s'", path)
+	}
+	var envelope passphraseEnvelope
+	if err := json.Unmarshal(block.Bytes, &envelope); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse encrypted kubeconfig '%s': %w", path, err)
+	}
+
+	passphrase, ok := kubeconfigPassphrases[path]
+	if !ok {
+		var err error
+		if passphrase, err = readPassphrase(kubeconfigPassphraseEnv, fmt.Sprintf("Passphrase for '%s'", path), false); err != nil {
+			return nil, nil, err
+		}
+	}
+	plaintext, err := envelope.open(passphrase, encryptedKubeconfigPurpose)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decrypt kubeconfig '%s': %w", path, err)
+	}
+	kubeconfigPassphrases[path] = passphrase
+	return plaintext, passphrase, nil
+}
+
+func init() {
+	encryptCmd.Flags().StringVarP(&encryptOutput, "output", "o", "", "Write the encrypted kubeconfig to this file ('-' for stdout) instead of in place")
+	decryptCmd.Flags().StringVarP(&decryptOutput, "output", "o", "", "Write the decrypted kubeconfig to this file ('-' for stdout) instead of in place")
+	rootCmd.AddCommand(encryptCmd)
+	rootCmd.AddCommand(decryptCmd)
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptCommands(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "kedit-test-encrypt-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+	t.Setenv("KEDIT_PASSPHRASE", "s3cret passphrase")
+
+	kubeconfigPath := filepath.Join(tempDir, "config")
+	original := `apiVersion: v1
+clusters:
+- cluster:
+    server: https://cluster1
+  name: cluster1
+contexts:
+- context:
+    cluster: cluster1
+    user: user1
+  name: context1
+current-context: context1
+kind: Config
+preferences: {}
+users:
+- name: user1
+  user:
+    token: secret-token
+`
+	err = ioutil.WriteFile(kubeconfigPath, []byte(original), 0644)
+	assert.NoError(t, err)
+
+	// Test that encrypting in place leaves no plaintext behind.
+	t.Run("encrypt", func(t *testing.T) {
+		output := executeCommandC(t, "encrypt", kubeconfigPath)
+		assert.Equal(t, "Encrypted '"+kubeconfigPath+"' to '"+kubeconfigPath+"'.", output)
+
+		content, err := ioutil.ReadFile(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.True(t, isEncryptedKubeconfig(content))
+		assert.NotContains(t, string(content), "secret-token")
+		assert.NotContains(t, string(content), "cluster1")
+
+		output = executeCommandC(t, "encrypt", kubeconfigPath)
+		assert.Contains(t, output, "Error: '"+kubeconfigPath+"' is already encrypted")
+	})
+
+	// Test that commands read and write the encrypted kubeconfig transparently.
+	t.Run("encrypt transparent", func(t *testing.T) {
+		output := executeCommandC(t, "list", "context", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "context1")
+
+		executeCommandC(t, "rename", "context", "context1", "main", "--kubeconfig", kubeconfigPath)
+		content, err := ioutil.ReadFile(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.True(t, isEncryptedKubeconfig(content))
+
+		config, err := loadKubeconfig(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.Contains(t, config.Contexts, "main")
+		assert.Equal(t, "secret-token", config.AuthInfos["user1"].Token)
+	})
+
+	// Test that a wrong passphrase is rejected and decrypting restores the file.
+	t.Run("decrypt", func(t *testing.T) {
+		delete(kubeconfigPassphrases, kubeconfigPath)
+		t.Setenv("KEDIT_PASSPHRASE", "wrong")
+		output := executeCommandC(t, "decrypt", kubeconfigPath, "-o", "-")
+		assert.Contains(t, output, "Error: failed to decrypt kubeconfig '"+kubeconfigPath+"': wrong passphrase or corrupted data")
+
+		t.Setenv("KEDIT_PASSPHRASE", "s3cret passphrase")
+		plainPath := filepath.Join(tempDir, "plain")
+		output = executeCommandC(t, "decrypt", kubeconfigPath, "-o", plainPath)
+		assert.Equal(t, "Decrypted '"+kubeconfigPath+"' to '"+plainPath+"'.", output)
+		content, err := ioutil.ReadFile(plainPath)
+		assert.NoError(t, err)
+		assert.Contains(t, string(content), "token: secret-token")
+		assert.Contains(t, string(content), "name: main")
+
+		output = executeCommandC(t, "decrypt", plainPath)
+		assert.Contains(t, output, "Error: '"+plainPath+"' is not encrypted")
+	})
+}
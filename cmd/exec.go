@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+var (
+	execContexts []string // Flag for glob patterns selecting the contexts
+	execParallel int      // Flag for the maximum number of commands running at once
+)
+
+// execResult is the outcome of running the command against one context.
+type execResult struct {
+	context  string
+	exitCode int
+	duration time.Duration
+	err      error // set if the command could not be run at all
+}
+
+// execCmd represents the exec command
+var execCmd = &cobra.Command{
+	Use:   "exec --contexts <pattern> [--parallel N] -- <command> [args...]",
+	Short: "Run a command against every context matching a pattern",
+	Long: `Run the same command against several contexts.
+
+For every context matching one of the --contexts glob patterns ('*' matches
+any characters, '?' a single one), kedit writes a temporary kubeconfig that
+contains only that context (see 'kedit run') and runs the command with
+KUBECONFIG pointing to it. Up to --parallel commands run at the same time.
+
+Every line of output is prefixed with the name of its context. A summary of
+the exit codes is printed at the end, and kedit exits non-zero if the command
+failed in any context.
+
+Example:
+  kedit exec --contexts 'staging-*' --parallel 4 -- kubectl get nodes`,
+	Args: cobra.MinimumNArgs(1), // Requires the command
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(execContexts) == 0 {
+			return fmt.Errorf("flag --contexts is required")
+		}
+		if execParallel < 1 {
+			return fmt.Errorf("flag --parallel must be at least 1")
+		}
+
+		config, err := loadKubeconfig(resolvedKubeconfigPath)
+		if err != nil {
+			return fmt.Errorf("error loading kubeconfig from '%s': %w", resolvedKubeconfigPath, err)
+		}
+
+		var contextNames []string
+		for _, name := range sortedKeys(config.Contexts) {
+			for _, pattern := range execContexts {
+				if matchGlob(pattern, name) {
+					contextNames = append(contextNames, name)
+					break
+				}
+			}
+		}
+		if len(contextNames) == 0 {
+			return fmt.Errorf("no contexts in '%s' match %s", resolvedKubeconfigPath, strings.Join(execContexts, ", "))
+		}
+
+		results := execInContexts(config, contextNames, args[0], args[1:])
+		failed := printExecSummary(results)
+		if failed > 0 {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("command failed in %d of %d context(s)", failed, len(results))
+		}
+		return nil
+	},
+}
+
+// execInContexts runs the command against each context, at most --parallel at
+// a time, and returns the results in the order of contextNames.
+func execInContexts(config *api.Config, contextNames []string, name string, args []string) []execResult {
+	width := 0
+	for _, contextName := range contextNames {
+		if len(contextName) > width {
+			width = len(contextName)
+		}
+	}
+
+	var (
+		outputMu  sync.Mutex // serializes output lines of all commands
+		processMu sync.Mutex // guards processes
+		processes []*os.Process
+	)
+	stop := relaySignals(func() []*os.Process {
+		processMu.Lock()
+		defer processMu.Unlock()
+		return append([]*os.Process{}, processes...)
+	})
+	defer stop()
+
+	results := make([]execResult, len(contextNames))
+	semaphore := make(chan struct{}, execParallel)
+	var wg sync.WaitGroup
+	for i, contextName := range contextNames {
+		wg.Add(1)
+		go func(i int, contextName string) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			prefix := fmt.Sprintf("[%-*s] ", width, contextName)
+			stdout := &prefixWriter{mu: &outputMu, out: os.Stdout, prefix: prefix}
+			stderr := &prefixWriter{mu: &outputMu, out: os.Stderr, prefix: prefix}
+			started := func(process *os.Process) {
+				processMu.Lock()
+				defer processMu.Unlock()
+				processes = append(processes, process)
+			}
+			results[i] = execInContext(config, contextName, name, args, stdout, stderr, started)
+			stdout.Flush()
+			stderr.Flush()
+		}(i, contextName)
+	}
+	wg.Wait()
+	return results
+}
+
+// execInContext runs the command with a temporary kubeconfig for one context.
+func execInContext(config *api.Config, contextName, name string, args []string, stdout, stderr io.Writer, started func(*os.Process)) (result execResult) {
+	result.context = contextName
+	start := time.Now()
+	defer func() { result.duration = time.Since(start) }()
+
+	path, cleanup, err := writeIsolatedKubeconfig(config, contextName)
+	if err != nil {
+		result.err = err
+		return result
+	}
+	defer cleanup()
+
+	c := exec.Command(name, args...)
+	c.Stdout = stdout
+	c.Stderr = stderr
+	c.Env = isolatedEnv(os.Environ(), path, contextName)
+	if err := c.Start(); err != nil {
+		result.err = fmt.Errorf("failed to run '%s': %w", name, err)
+		return result
+	}
+	started(c.Process)
+
+	if err := c.Wait(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			result.err = fmt.Errorf("failed to run '%s': %w", name, err)
+			return result
+		}
+		result.exitCode = exitErr.ExitCode()
+	}
+	return result
+}
+
+// printExecSummary prints a table of the results and returns the number of
+// contexts in which the command failed.
+func printExecSummary(results []execResult) int {
+	failed := 0
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CONTEXT\tEXIT CODE\tDURATION\tERROR")
+	for _, result := range results {
+		exitCode := fmt.Sprintf("%d", result.exitCode)
+		errText := ""
+		if result.err != nil {
+			exitCode = "-"
+			errText = result.err.Error()
+		}
+		if result.err != nil || result.exitCode != 0 {
+			failed++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", result.context, exitCode, result.duration.Round(time.Millisecond), errText)
+	}
+	w.Flush()
+	return failed
+}
+
+// prefixWriter writes every complete line it receives to out, preceded by
+// prefix. Lines of writers sharing mu are never interleaved.
+type prefixWriter struct {
+	mu     *sync.Mutex
+	out    io.Writer
+	prefix string
+	buf    []byte
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		w.writeLine(w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+}
+
+// Flush writes a final line that did not end with a newline.
+func (w *prefixWriter) Flush() {
+	if len(w.buf) > 0 {
+		w.writeLine(w.buf)
+		w.buf = nil
+	}
+}
+
+func (w *prefixWriter) writeLine(line []byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	fmt.Fprintf(w.out, "%s%s\n", w.prefix, line)
+}
+
+func init() {
+	execCmd.Flags().StringArrayVar(&execContexts, "contexts", nil, "Glob pattern of the contexts to run the command against (can be repeated)")
+	execCmd.Flags().IntVar(&execParallel, "parallel", 4, "Maximum number of commands running at the same time")
+	execCmd.RegisterFlagCompletionFunc("contexts", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return completeTargetNames("context", toComplete, nil)
+	})
+	rootCmd.AddCommand(execCmd)
+}
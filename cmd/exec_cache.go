@@ -0,0 +1,314 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+var (
+	execCacheName    string        // Flag for the user whose credential is cached
+	execCacheMargin  time.Duration // Flag for how long before expiry a credential is refreshed
+	execCacheKeyEnv  []string      // Flag for environment variables that select the credential
+	execCacheCommand string        // Flag for the command kubectl runs instead of the plugin
+)
+
+// execCacheCmd represents the exec-cache command
+var execCacheCmd = &cobra.Command{
+	Use:   "exec-cache",
+	Short: "Cache the credentials of slow exec plugins",
+	Long: `Cache the credentials returned by slow exec credential plugins, such as
+'aws eks get-token' or 'gke-gcloud-auth-plugin', so that they only run when the
+cached credential is about to expire.
+
+'kedit exec-cache wrap' rewrites the exec plugin of a user to run through
+'kedit exec-cache run', and 'kedit exec-cache unwrap' restores it.`,
+}
+
+// execCacheWrapCmd represents the exec-cache wrap command
+var execCacheWrapCmd = &cobra.Command{
+	Use:   "wrap <user_name>... [--margin <duration>] [--key-env <name>]...",
+	Short: "Run the exec plugin of users through the cache",
+	Long: `Rewrite the exec plugin of each user to run 'kedit exec-cache run -- <plugin>'.
+
+The plugin keeps its arguments, environment and other settings. Credentials
+are cached until their expirationTimestamp minus --margin; credentials without
+an expiry are not cached. The values of the variables in the env of the plugin
+are part of the cache key; wrap the user again after adding variables.
+
+Plugins often select the account from variables inherited from the shell, such
+as AWS_PROFILE, AWS_REGION or GOOGLE_APPLICATION_CREDENTIALS. Name them with
+--key-env so that changing them does not return the credential of another
+account.
+
+The wrapped plugin runs 'kedit' as found on kubectl's PATH, so that it keeps
+working after kedit is upgraded or moved. --command writes another command,
+such as an absolute path, instead.`,
+	Args: cobra.MinimumNArgs(1), // Requires at least one user_name
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return completeTargetNames("user", toComplete, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadKubeconfig(resolvedKubeconfigPath)
+		if err != nil {
+			return fmt.Errorf("error loading kubeconfig from '%s': %w", resolvedKubeconfigPath, err)
+		}
+		for _, name := range args {
+			authInfo, ok := config.AuthInfos[name]
+			if !ok {
+				return fmt.Errorf("user '%s' not found in '%s'", name, resolvedKubeconfigPath)
+			}
+			if authInfo.Exec == nil {
+				return fmt.Errorf("user '%s' does not use an exec plugin", name)
+			}
+			if _, ok := unwrappedExecCommand(authInfo.Exec); ok {
+				return fmt.Errorf("the exec plugin of user '%s' is already cached", name)
+			}
+		}
+
+		for _, name := range args {
+			execConfig := config.AuthInfos[name].Exec
+			wrapped := []string{"exec-cache", "run", "--name", name}
+			if cmd.Flags().Changed("margin") {
+				wrapped = append(wrapped, "--margin", execCacheMargin.String())
+			}
+			keyEnv := make(map[string]bool)
+			for _, env := range execConfig.Env {
+				keyEnv[env.Name] = true
+			}
+			for _, name := range execCacheKeyEnv {
+				keyEnv[name] = true
+			}
+			for _, name := range sortedKeys(keyEnv) {
+				wrapped = append(wrapped, "--key-env", name)
+			}
+			wrapped = append(wrapped, "--", execConfig.Command)
+			execConfig.Args = append(wrapped, execConfig.Args...)
+			execConfig.Command = execCacheCommand
+		}
+
+		if err := saveKubeconfig(config, resolvedKubeconfigPath); err != nil {
+			return fmt.Errorf("error saving kubeconfig to '%s' after wrapping exec plugins: %w", resolvedKubeconfigPath, err)
+		}
+		for _, name := range args {
+			fmt.Printf("The exec plugin of user '%s' now runs through the credential cache.\n", name)
+		}
+		return nil
+	},
+}
+
+// execCacheUnwrapCmd represents the exec-cache unwrap command
+var execCacheUnwrapCmd = &cobra.Command{
+	Use:   "unwrap <user_name>...",
+	Short: "Restore the original exec plugin of users",
+	Long:  `Restore the exec plugin of each user wrapped with 'kedit exec-cache wrap'.`,
+	Args:  cobra.MinimumNArgs(1), // Requires at least one user_name
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return completeTargetNames("user", toComplete, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadKubeconfig(resolvedKubeconfigPath)
+		if err != nil {
+			return fmt.Errorf("error loading kubeconfig from '%s': %w", resolvedKubeconfigPath, err)
+		}
+		originals := make(map[string][]string)
+		for _, name := range args {
+			authInfo, ok := config.AuthInfos[name]
+			if !ok {
+				return fmt.Errorf("user '%s' not found in '%s'", name, resolvedKubeconfigPath)
+			}
+			original, ok := unwrappedExecCommand(authInfo.Exec)
+			if !ok {
+				return fmt.Errorf("the exec plugin of user '%s' is not cached", name)
+			}
+			originals[name] = original
+		}
+
+		for _, name := range args {
+			execConfig := config.AuthInfos[name].Exec
+			execConfig.Command, execConfig.Args = originals[name][0], originals[name][1:]
+			if len(execConfig.Args) == 0 {
+				execConfig.Args = nil
+			}
+		}
+		if err := saveKubeconfig(config, resolvedKubeconfigPath); err != nil {
+			return fmt.Errorf("error saving kubeconfig to '%s' after unwrapping exec plugins: %w", resolvedKubeconfigPath, err)
+		}
+		for _, name := range args {
+			fmt.Printf("Restored the exec plugin of user '%s'.\n", name)
+		}
+		return nil
+	},
+}
+
+// execCacheRunCmd represents the exec-cache run command
+var execCacheRunCmd = &cobra.Command{
+	Use:   "run --name <user_name> [--margin <duration>] -- <command> [args...]",
+	Short: "Run an exec plugin, caching its credential",
+	Long: `Print the cached ExecCredential of the given plugin command if it is still
+valid, otherwise run the command and cache the credential it prints until its
+expirationTimestamp minus --margin.
+
+The cache key covers the user name, the command, the values of the --key-env
+variables and $KUBERNETES_EXEC_INFO. A cached credential is returned as long as
+these do not change, even if other variables the plugin reads do.
+
+This command is run by kubectl for users wrapped with 'kedit exec-cache wrap'.
+Cached credentials are stored with owner-only permissions in the user's cache
+directory.`,
+	Args: cobra.MinimumNArgs(1), // Requires the command
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := execCachePath(execCacheName, args, execCacheEnv(execCacheKeyEnv))
+		if err != nil {
+			return err
+		}
+		if cached, ok := readCachedCredential(path, time.Now().Add(execCacheMargin)); ok {
+			os.Stdout.Write(cached)
+			return nil
+		}
+
+		c := exec.Command(args[0], args[1:]...)
+		var stdout bytes.Buffer
+		c.Stdin = os.Stdin
+		c.Stdout = &stdout
+		c.Stderr = os.Stderr
+		if err := c.Run(); err != nil {
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				cmd.SilenceErrors = true
+				cmd.SilenceUsage = true
+				return &exitCodeError{code: exitErr.ExitCode()}
+			}
+			return fmt.Errorf("failed to run '%s': %w", args[0], err)
+		}
+
+		os.Stdout.Write(stdout.Bytes())
+		if _, ok := credentialExpiry(stdout.Bytes()); ok {
+			// A failure to cache only makes the next call slower.
+			if err := writeCachedCredential(path, stdout.Bytes()); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+		}
+		return nil
+	},
+}
+
+// unwrappedExecCommand returns the original command and arguments of an exec
+// plugin wrapped by exec-cache wrap.
+func unwrappedExecCommand(execConfig *api.ExecConfig) ([]string, bool) {
+	if execConfig == nil || len(execConfig.Args) < 2 || execConfig.Args[0] != "exec-cache" || execConfig.Args[1] != "run" {
+		return nil, false
+	}
+	for i, arg := range execConfig.Args {
+		if arg == "--" && i+1 < len(execConfig.Args) {
+			return execConfig.Args[i+1:], true
+		}
+	}
+	return nil, false
+}
+
+// execCachePath returns the cache file for the credential of a user obtained
+// by a command with the given environment.
+func execCachePath(name string, command, env []string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user cache directory: %w", err)
+	}
+	key := append(append([]string{name}, command...), "")
+	sum := sha256.Sum256([]byte(strings.Join(append(key, env...), "\x00")))
+	return filepath.Join(dir, "kedit", "exec-cache", hex.EncodeToString(sum[:16])+".json"), nil
+}
+
+// execCacheEnv returns the variables that select the credential of an exec
+// plugin as sorted "NAME=value" pairs: the given names and the cluster
+// information kubectl passes in $KUBERNETES_EXEC_INFO.
+func execCacheEnv(names []string) []string {
+	var env []string
+	for _, name := range names {
+		env = append(env, name+"="+os.Getenv(name))
+	}
+	if info := os.Getenv("KUBERNETES_EXEC_INFO"); info != "" {
+		env = append(env, "KUBERNETES_EXEC_INFO="+normalizeExecInfo(info))
+	}
+	sort.Strings(env)
+	return env
+}
+
+// normalizeExecInfo drops spec.interactive from $KUBERNETES_EXEC_INFO, which
+// only tells whether kubectl runs in a terminal and does not change the credential.
+func normalizeExecInfo(info string) string {
+	var execInfo map[string]interface{}
+	if err := json.Unmarshal([]byte(info), &execInfo); err != nil {
+		return info
+	}
+	if spec, ok := execInfo["spec"].(map[string]interface{}); ok {
+		delete(spec, "interactive")
+	}
+	normalized, err := json.Marshal(execInfo)
+	if err != nil {
+		return info
+	}
+	return string(normalized)
+}
+
+// readCachedCredential returns the cached credential if it is still valid at validUntil.
+func readCachedCredential(path string, validUntil time.Time) ([]byte, bool) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	expiry, ok := credentialExpiry(content)
+	if !ok || !expiry.After(validUntil) {
+		return nil, false
+	}
+	return content, true
+}
+
+// credentialExpiry returns the expirationTimestamp of an ExecCredential, if it has one.
+func credentialExpiry(content []byte) (time.Time, bool) {
+	var credential execCredential
+	if err := json.Unmarshal(content, &credential); err != nil || credential.Status == nil || credential.Status.ExpirationTimestamp == "" {
+		return time.Time{}, false
+	}
+	expiry, err := time.Parse(time.RFC3339, credential.Status.ExpirationTimestamp)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return expiry, true
+}
+
+// writeCachedCredential stores a credential with owner-only permissions.
+func writeCachedCredential(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create cache directory '%s': %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		return fmt.Errorf("failed to cache credential in '%s': %w", path, err)
+	}
+	return nil
+}
+
+func init() {
+	execCacheWrapCmd.Flags().DurationVar(&execCacheMargin, "margin", time.Minute, "Refresh cached credentials this long before they expire")
+	execCacheRunCmd.Flags().DurationVar(&execCacheMargin, "margin", time.Minute, "Refresh cached credentials this long before they expire")
+	execCacheRunCmd.Flags().StringVar(&execCacheName, "name", "", "Name of the user whose credential is cached")
+	execCacheRunCmd.Flags().StringArrayVar(&execCacheKeyEnv, "key-env", nil, "Environment variable whose value is part of the cache key (can be repeated)")
+	execCacheWrapCmd.Flags().StringArrayVar(&execCacheKeyEnv, "key-env", nil, "Inherited environment variable whose value is part of the cache key (can be repeated)")
+	execCacheWrapCmd.Flags().StringVar(&execCacheCommand, "command", "kedit", "Command the wrapped plugin runs, looked up in PATH unless it is a path")
+	execCacheCmd.AddCommand(execCacheWrapCmd)
+	execCacheCmd.AddCommand(execCacheUnwrapCmd)
+	execCacheCmd.AddCommand(execCacheRunCmd)
+	rootCmd.AddCommand(execCacheCmd)
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecCacheCommands(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "kedit-test-exec-cache-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(tempDir, "cache"))
+
+	kubeconfigPath := filepath.Join(tempDir, "config")
+	err = ioutil.WriteFile(kubeconfigPath, []byte(`
+apiVersion: v1
+clusters:
+- cluster:
+    server: https://cluster1
+  name: cluster1
+contexts:
+- context:
+    cluster: cluster1
+    user: eks
+  name: context1
+current-context: context1
+kind: Config
+preferences: {}
+users:
+- name: eks
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1beta1
+      command: aws
+      args:
+      - eks
+      - get-token
+      - --cluster-name
+      - cluster1
+      env:
+      - name: AWS_PROFILE
+        value: prod
+- name: static
+  user:
+    token: token1
+`), 0644)
+	assert.NoError(t, err)
+
+	// Test that wrapping and unwrapping round-trips the exec plugin.
+	t.Run("exec-cache wrap and unwrap", func(t *testing.T) {
+		output := executeCommandC(t, "exec-cache", "wrap", "eks", "--margin", "5m", "--kubeconfig", kubeconfigPath)
+		assert.Equal(t, "The exec plugin of user 'eks' now runs through the credential cache.", output)
+
+		config, err := loadKubeconfig(kubeconfigPath)
+		assert.NoError(t, err)
+		exec := config.AuthInfos["eks"].Exec
+		assert.Equal(t, "kedit", exec.Command)
+		assert.Equal(t, []string{"exec-cache", "run", "--name", "eks", "--margin", "5m0s", "--key-env", "AWS_PROFILE", "--", "aws", "eks", "get-token", "--cluster-name", "cluster1"}, exec.Args)
+		assert.Equal(t, "prod", exec.Env[0].Value)
+
+		output = executeCommandC(t, "exec-cache", "wrap", "eks", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Error: the exec plugin of user 'eks' is already cached")
+		output = executeCommandC(t, "exec-cache", "wrap", "static", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Error: user 'static' does not use an exec plugin")
+
+		output = executeCommandC(t, "exec-cache", "unwrap", "eks", "--kubeconfig", kubeconfigPath)
+		assert.Equal(t, "Restored the exec plugin of user 'eks'.", output)
+		config, err = loadKubeconfig(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.Equal(t, "aws", config.AuthInfos["eks"].Exec.Command)
+		assert.Equal(t, []string{"eks", "get-token", "--cluster-name", "cluster1"}, config.AuthInfos["eks"].Exec.Args)
+
+		// Inherited variables named with --key-env are added to the key.
+		executeCommandC(t, "exec-cache", "wrap", "eks", "--key-env", "AWS_REGION", "--key-env", "AWS_PROFILE", "--kubeconfig", kubeconfigPath)
+		config, err = loadKubeconfig(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"exec-cache", "run", "--name", "eks", "--key-env", "AWS_PROFILE", "--key-env", "AWS_REGION", "--", "aws", "eks", "get-token", "--cluster-name", "cluster1"}, config.AuthInfos["eks"].Exec.Args)
+		executeCommandC(t, "exec-cache", "unwrap", "eks", "--kubeconfig", kubeconfigPath)
+	})
+
+	// Test that credentials are cached until shortly before they expire.
+	t.Run("exec-cache run", func(t *testing.T) {
+		countPath := filepath.Join(tempDir, "count")
+		pluginPath := filepath.Join(tempDir, "plugin.sh")
+		writePlugin := func(expiry time.Time) {
+			script := fmt.Sprintf(`#!/bin/sh
+echo x >> %s
+echo '{"apiVersion":"client.authentication.k8s.io/v1","kind":"ExecCredential","status":{"token":"t","expirationTimestamp":"%s"}}'
+`, countPath, expiry.UTC().Format(time.RFC3339))
+			assert.NoError(t, ioutil.WriteFile(pluginPath, []byte(script), 0755))
+		}
+		runs := func() int {
+			content, _ := ioutil.ReadFile(countPath)
+			return strings.Count(string(content), "x")
+		}
+
+		writePlugin(time.Now().Add(time.Hour))
+		first := executeCommandC(t, "exec-cache", "run", "--name", "eks", "--", pluginPath)
+		second := executeCommandC(t, "exec-cache", "run", "--name", "eks", "--", pluginPath)
+		assert.Contains(t, first, `"token":"t"`)
+		assert.Equal(t, first, second)
+		assert.Equal(t, 1, runs())
+
+		// Another user is cached separately.
+		executeCommandC(t, "exec-cache", "run", "--name", "other", "--", pluginPath)
+		assert.Equal(t, 2, runs())
+
+		// A credential expiring within the margin is refreshed.
+		writePlugin(time.Now().Add(30 * time.Second))
+		executeCommandC(t, "exec-cache", "run", "--name", "short", "--", pluginPath)
+		executeCommandC(t, "exec-cache", "run", "--name", "short", "--", pluginPath)
+		assert.Equal(t, 4, runs())
+
+		// Another profile or cluster is cached separately, whether kubectl runs in a terminal or not.
+		writePlugin(time.Now().Add(time.Hour))
+		t.Setenv("AWS_PROFILE", "prod")
+		t.Setenv("KUBERNETES_EXEC_INFO", `{"kind":"ExecCredential","spec":{"interactive":false,"cluster":{"server":"https://cluster1"}}}`)
+		executeCommandC(t, "exec-cache", "run", "--name", "eks", "--key-env", "AWS_PROFILE", "--", pluginPath)
+		assert.Equal(t, 5, runs())
+		t.Setenv("KUBERNETES_EXEC_INFO", `{"kind":"ExecCredential","spec":{"interactive":true,"cluster":{"server":"https://cluster1"}}}`)
+		executeCommandC(t, "exec-cache", "run", "--name", "eks", "--key-env", "AWS_PROFILE", "--", pluginPath)
+		assert.Equal(t, 5, runs())
+		t.Setenv("AWS_PROFILE", "staging")
+		executeCommandC(t, "exec-cache", "run", "--name", "eks", "--key-env", "AWS_PROFILE", "--", pluginPath)
+		assert.Equal(t, 6, runs())
+		t.Setenv("KUBERNETES_EXEC_INFO", `{"kind":"ExecCredential","spec":{"interactive":true,"cluster":{"server":"https://cluster2"}}}`)
+		executeCommandC(t, "exec-cache", "run", "--name", "eks", "--key-env", "AWS_PROFILE", "--", pluginPath)
+		assert.Equal(t, 7, runs())
+
+		path, err := execCachePath("eks", []string{pluginPath}, nil)
+		assert.NoError(t, err)
+		info, err := os.Stat(path)
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	})
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecCommand(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "kedit-test-exec-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	kubeconfigPath := filepath.Join(tempDir, "config")
+	err = ioutil.WriteFile(kubeconfigPath, []byte(`
+apiVersion: v1
+clusters:
+- cluster:
+    server: https://cluster1
+  name: cluster1
+contexts:
+- context:
+    cluster: cluster1
+    user: user1
+  name: staging-eu
+- context:
+    cluster: cluster1
+    user: user1
+  name: staging-us
+- context:
+    cluster: cluster1
+    user: user1
+  name: prod
+- context:
+    cluster: missing
+  name: staging-broken
+current-context: prod
+kind: Config
+preferences: {}
+users:
+- name: user1
+  user:
+    token: token1
+`), 0644)
+	assert.NoError(t, err)
+
+	// Test prefixed output and the summary when every command succeeds.
+	t.Run("exec success", func(t *testing.T) {
+		output := executeCommandC(t, "exec", "--contexts", "staging-e*", "--contexts", "staging-us", "--parallel", "2",
+			"--kubeconfig", kubeconfigPath, "--", "sh", "-c", `echo "using $KEDIT_CONTEXT"; grep -c '^- context:' "$KUBECONFIG"`)
+
+		assert.Contains(t, output, "[staging-eu] using staging-eu\n")
+		assert.Contains(t, output, "[staging-eu] 1\n")
+		assert.Contains(t, output, "[staging-us] using staging-us\n")
+		assert.NotContains(t, output, "prod")
+		assert.Regexp(t, regexp.MustCompile(`CONTEXT\s+EXIT CODE\s+DURATION\s+ERROR\nstaging-eu\s+0\s+\S+\s*\nstaging-us\s+0\s+\S+`), output)
+		assert.NotContains(t, output, "Error:")
+	})
+
+	// Test that failing contexts are summarized and make the command fail.
+	t.Run("exec failure", func(t *testing.T) {
+		output := executeCommandC(t, "exec", "--contexts", "staging-*", "--kubeconfig", kubeconfigPath, "--",
+			"sh", "-c", `test "$KEDIT_CONTEXT" != staging-us || { echo boom >&2; exit 2; }`)
+
+		assert.Contains(t, output, "[staging-us    ] boom")
+		assert.Regexp(t, regexp.MustCompile(`staging-broken\s+-\s+\S+\s+cluster 'missing' \(referenced by context 'staging-broken'\) not found`), output)
+		assert.Regexp(t, regexp.MustCompile(`staging-eu\s+0\s`), output)
+		assert.Regexp(t, regexp.MustCompile(`staging-us\s+2\s`), output)
+		assert.Contains(t, output, "Error: command failed in 2 of 3 context(s)")
+		assert.NotContains(t, output, "Usage:")
+	})
+
+	// Test invalid invocations.
+	t.Run("exec invalid", func(t *testing.T) {
+		output := executeCommandC(t, "exec", "--kubeconfig", kubeconfigPath, "--", "true")
+		assert.Contains(t, output, "Error: flag --contexts is required")
+
+		output = executeCommandC(t, "exec", "--contexts", "dev-*", "--kubeconfig", kubeconfigPath, "--", "true")
+		assert.Contains(t, output, "Error: no contexts in '"+kubeconfigPath+"' match dev-*")
+	})
+}
@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+var (
+	extractOutput  string // Flag for the output file path ("-" for stdout)
+	extractFlatten bool   // Flag to inline referenced certificate, key and token files
+	extractBase64  bool   // Flag to base64-encode the generated kubeconfig
+	extractDir     string // Flag for the directory to write one kubeconfig per context into
+)
+
+// unsafeFileNameChars matches characters that should not appear in a file name derived from an item name.
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// extractCmd represents the extract command
+var extractCmd = &cobra.Command{
+	Use:   "extract <context_name>... [-o <file>|-] [--dir <directory>]",
+	Short: "Export contexts as a standalone kubeconfig",
+	Long: `Export one or more contexts, along with their referenced clusters and users,
+from the target kubeconfig file into a new, minimal kubeconfig. This is the
+inverse of the merge command.
+
+The current-context of the generated kubeconfig is set to the target's
+current-context if it is among the extracted contexts, otherwise to the first
+context given on the command line.
+
+Relative file references (certificates, keys) are resolved to absolute paths
+so that the generated kubeconfig works from any location.
+
+--output (or -o) specifies the output file; '-' (the default) writes to stdout.
+--flatten inlines referenced certificate, key and token files as embedded data.
+--base64 encodes the generated kubeconfig as base64, e.g. for CI variables.
+--dir writes one kubeconfig per context into the given directory instead,
+named after the context; names with characters other than letters, digits,
+'.', '_' and '-' get a short hash suffix so that they never share a file.`,
+	Args:              cobra.MinimumNArgs(1), // Requires at least one context_name
+	ValidArgsFunction: completeContextNames,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if extractDir != "" && cmd.Flags().Changed("output") {
+			return fmt.Errorf("flags --dir and --output cannot be used together")
+		}
+
+		config, err := loadKubeconfig(resolvedKubeconfigPath)
+		if err != nil {
+			return fmt.Errorf("error loading kubeconfig from '%s': %w", resolvedKubeconfigPath, err)
+		}
+
+		if extractDir != "" {
+			dir, err := homedir.Expand(extractDir)
+			if err != nil {
+				return fmt.Errorf("error expanding directory path '%s': %w", extractDir, err)
+			}
+			for _, contextName := range args {
+				extracted, err := extractContexts(config, []string{contextName})
+				if err != nil {
+					return err
+				}
+				filePath := filepath.Join(dir, safeFileName(contextName)+".yaml")
+				if err := writeExtractedConfig(extracted, filePath); err != nil {
+					return err
+				}
+				fmt.Printf("Extracted context '%s' to '%s'.\n", contextName, filePath)
+			}
+			return nil
+		}
+
+		extracted, err := extractContexts(config, args)
+		if err != nil {
+			return err
+		}
+
+		if extractOutput == "-" {
+			content, err := encodeExtractedConfig(extracted)
+			if err != nil {
+				return err
+			}
+			_, err = os.Stdout.Write(content)
+			return err
+		}
+
+		outputPath, err := homedir.Expand(extractOutput)
+		if err != nil {
+			return fmt.Errorf("error expanding output path '%s': %w", extractOutput, err)
+		}
+		if err := writeExtractedConfig(extracted, outputPath); err != nil {
+			return err
+		}
+		fmt.Printf("Extracted %d context(s) from '%s' to '%s'.\n", len(extracted.Contexts), resolvedKubeconfigPath, outputPath)
+		return nil
+	},
+}
+
+// extractContexts builds a new kubeconfig containing only the named contexts
+// and the clusters and users they reference. The source config is not modified.
+func extractContexts(config *api.Config, contextNames []string) (*api.Config, error) {
+	extracted := api.NewConfig()
+	for _, contextName := range contextNames {
+		context, ok := config.Contexts[contextName]
+		if !ok {
+			return nil, fmt.Errorf("context '%s' not found in '%s'", contextName, resolvedKubeconfigPath)
+		}
+		extracted.Contexts[contextName] = context.DeepCopy()
+
+		if context.Cluster != "" {
+			cluster, ok := config.Clusters[context.Cluster]
+			if !ok {
+				return nil, fmt.Errorf("cluster '%s' (referenced by context '%s') not found in '%s'", context.Cluster, contextName, resolvedKubeconfigPath)
+			}
+			extracted.Clusters[context.Cluster] = cluster.DeepCopy()
+		}
+
+		if context.AuthInfo != "" {
+			user, ok := config.AuthInfos[context.AuthInfo]
+			if !ok {
+				return nil, fmt.Errorf("user '%s' (referenced by context '%s') not found in '%s'", context.AuthInfo, contextName, resolvedKubeconfigPath)
+			}
+			extracted.AuthInfos[context.AuthInfo] = user.DeepCopy()
+		}
+	}
+
+	if _, ok := extracted.Contexts[config.CurrentContext]; ok {
+		extracted.CurrentContext = config.CurrentContext
+	} else if len(contextNames) > 0 {
+		extracted.CurrentContext = contextNames[0]
+	}
+
+	// File references are relative to the source kubeconfig, so make them absolute
+	// before the extracted config is written anywhere else.
+	if err := clientcmd.ResolveLocalPaths(extracted); err != nil {
+		return nil, fmt.Errorf("error resolving file references: %w", err)
+	}
+	if extractFlatten {
+		if _, err := flattenConfig(extracted); err != nil {
+			return nil, err
+		}
+	}
+	return extracted, nil
+}
+
+// encodeExtractedConfig serializes the config to YAML, base64-encoding it if requested.
+func encodeExtractedConfig(config *api.Config) ([]byte, error) {
+	content, err := clientcmd.Write(*config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize extracted kubeconfig: %w", err)
+	}
+	if extractBase64 {
+		return []byte(base64.StdEncoding.EncodeToString(content) + "\n"), nil
+	}
+	return content, nil
+}
+
+// writeExtractedConfig writes the config to filePath with owner-only permissions.
+func writeExtractedConfig(config *api.Config, filePath string) error {
+	content, err := encodeExtractedConfig(config)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory '%s': %w", filepath.Dir(filePath), err)
+	}
+	if err := os.WriteFile(filePath, content, 0600); err != nil {
+		return fmt.Errorf("failed to write extracted kubeconfig to '%s': %w", filePath, err)
+	}
+	return nil
+}
+
+func init() {
+	extractCmd.Flags().StringVarP(&extractOutput, "output", "o", "-", "Output file path, '-' for stdout")
+	extractCmd.Flags().BoolVar(&extractFlatten, "flatten", false, "Inline referenced certificate, key and token files")
+	extractCmd.Flags().BoolVar(&extractBase64, "base64", false, "Base64-encode the generated kubeconfig")
+	extractCmd.Flags().StringVar(&extractDir, "dir", "", "Write one kubeconfig per context into this directory")
+	rootCmd.AddCommand(extractCmd)
+}
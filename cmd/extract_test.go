@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func TestExtractCommand(t *testing.T) {
+	// Helper to create a kubeconfig with two contexts and a file-based CA.
+	createKubeconfigForExtract := func(tempDir string) string {
+		err := ioutil.WriteFile(filepath.Join(tempDir, "ca.crt"), []byte("test-ca-data"), 0644)
+		assert.NoError(t, err)
+
+		kubeconfigPath := filepath.Join(tempDir, "config")
+		err = ioutil.WriteFile(kubeconfigPath, []byte(`
+apiVersion: v1
+clusters:
+- cluster:
+    server: https://cluster1
+    certificate-authority: ca.crt
+  name: cluster1
+- cluster:
+    server: https://cluster2
+  name: cluster2
+contexts:
+- context:
+    cluster: cluster1
+    user: user1
+  name: context1
+- context:
+    cluster: cluster2
+    user: user2
+  name: context2
+current-context: context2
+kind: Config
+preferences: {}
+users:
+- name: user1
+  user:
+    token: token1
+- name: user2
+  user:
+    token: token2
+`), 0644)
+		assert.NoError(t, err)
+		return kubeconfigPath
+	}
+
+	// Test extracting a single context to a file.
+	t.Run("extract context to file", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-extract-file-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		kubeconfigPath := createKubeconfigForExtract(tempDir)
+		outputPath := filepath.Join(tempDir, "out", "extracted")
+
+		output := executeCommandC(t, "extract", "context1", "-o", outputPath, "--kubeconfig", kubeconfigPath)
+		expectedOutput := "Extracted 1 context(s) from '" + kubeconfigPath + "' to '" + outputPath + "'."
+		assert.Equal(t, expectedOutput, output)
+
+		info, err := os.Stat(outputPath)
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+		config, err := clientcmd.LoadFromFile(outputPath)
+		assert.NoError(t, err)
+		assert.Equal(t, "context1", config.CurrentContext)
+		assert.Len(t, config.Contexts, 1)
+		assert.Len(t, config.Clusters, 1)
+		assert.Len(t, config.AuthInfos, 1)
+		assert.Equal(t, "token1", config.AuthInfos["user1"].Token)
+		// The relative CA path must be resolved against the source kubeconfig.
+		assert.Equal(t, filepath.Join(tempDir, "ca.crt"), config.Clusters["cluster1"].CertificateAuthority)
+	})
+
+	// Test extracting with --flatten to stdout keeps the current-context.
+	t.Run("extract flatten to stdout", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-extract-flatten-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		kubeconfigPath := createKubeconfigForExtract(tempDir)
+
+		output := executeCommandC(t, "extract", "context1", "context2", "--flatten", "--kubeconfig", kubeconfigPath)
+		config, err := clientcmd.Load([]byte(output))
+		assert.NoError(t, err)
+		assert.Equal(t, "context2", config.CurrentContext)
+		assert.Len(t, config.Contexts, 2)
+		assert.Empty(t, config.Clusters["cluster1"].CertificateAuthority)
+		assert.Equal(t, []byte("test-ca-data"), config.Clusters["cluster1"].CertificateAuthorityData)
+	})
+
+	// Test extracting as base64.
+	t.Run("extract base64", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-extract-base64-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		kubeconfigPath := createKubeconfigForExtract(tempDir)
+
+		output := executeCommandC(t, "extract", "context2", "--base64", "--kubeconfig", kubeconfigPath)
+		decoded, err := base64.StdEncoding.DecodeString(output)
+		assert.NoError(t, err)
+		config, err := clientcmd.Load(decoded)
+		assert.NoError(t, err)
+		assert.Equal(t, "context2", config.CurrentContext)
+		assert.NotNil(t, config.Clusters["cluster2"])
+	})
+
+	// Test extracting one file per context.
+	t.Run("extract dir", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-extract-dir-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		kubeconfigPath := createKubeconfigForExtract(tempDir)
+		outputDir := filepath.Join(tempDir, "contexts")
+
+		output := executeCommandC(t, "extract", "context1", "context2", "--dir", outputDir, "--kubeconfig", kubeconfigPath)
+		expectedOutput := "Extracted context 'context1' to '" + filepath.Join(outputDir, "context1.yaml") + "'.\n" +
+			"Extracted context 'context2' to '" + filepath.Join(outputDir, "context2.yaml") + "'."
+		assert.Equal(t, expectedOutput, output)
+
+		config, err := clientcmd.LoadFromFile(filepath.Join(outputDir, "context2.yaml"))
+		assert.NoError(t, err)
+		assert.Equal(t, "context2", config.CurrentContext)
+		assert.Len(t, config.Contexts, 1)
+		assert.NotNil(t, config.AuthInfos["user2"])
+	})
+
+	// Test that contexts whose names only differ in unsafe characters get their own files.
+	t.Run("extract dir colliding names", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-extract-dir-collide-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		kubeconfigPath := createKubeconfigForExtract(tempDir)
+		config, err := loadKubeconfig(kubeconfigPath)
+		assert.NoError(t, err)
+		config.Contexts["a/b"] = config.Contexts["context1"]
+		config.Contexts["a_b"] = config.Contexts["context2"]
+		assert.NoError(t, saveKubeconfig(config, kubeconfigPath))
+		outputDir := filepath.Join(tempDir, "contexts")
+
+		executeCommandC(t, "extract", "a/b", "a_b", "--dir", outputDir, "--kubeconfig", kubeconfigPath)
+		files, err := ioutil.ReadDir(outputDir)
+		assert.NoError(t, err)
+		assert.Len(t, files, 2)
+
+		config, err = clientcmd.LoadFromFile(filepath.Join(outputDir, safeFileName("a/b")+".yaml"))
+		assert.NoError(t, err)
+		assert.NotNil(t, config.Contexts["a/b"])
+		config, err = clientcmd.LoadFromFile(filepath.Join(outputDir, "a_b.yaml"))
+		assert.NoError(t, err)
+		assert.NotNil(t, config.Contexts["a_b"])
+	})
+
+	// Test extracting a non-existent context.
+	t.Run("extract non-existent context", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-extract-nonexistent-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		kubeconfigPath := createKubeconfigForExtract(tempDir)
+
+		output := executeCommandC(t, "extract", "missing", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Error: context 'missing' not found in '"+kubeconfigPath+"'")
+	})
+}
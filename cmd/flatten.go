@@ -0,0 +1,403 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+var (
+	unflattenDir string // Flag for the directory that receives the unflattened files
+)
+
+// flattenCmd represents the flatten command
+var flattenCmd = &cobra.Command{
+	Use:   "flatten [(cluster|user) <name>]",
+	Short: "Inline referenced certificate, key and token files",
+	Long: `Read the files referenced by certificate-authority, client-certificate,
+client-key and tokenFile and store their contents in the corresponding
+certificate-authority-data, client-certificate-data, client-key-data and
+token fields, so the kubeconfig no longer depends on files on disk.
+
+Without arguments every cluster and user is flattened. With a type and a
+name, only that cluster or user is flattened.`,
+	Args:              flattenArgs,
+	ValidArgsFunction: completeTypeThenName([]string{"cluster", "user"}),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadKubeconfig(resolvedKubeconfigPath)
+		if err != nil {
+			return fmt.Errorf("error loading kubeconfig from '%s': %w", resolvedKubeconfigPath, err)
+		}
+
+		count := 0
+		if len(args) == 0 {
+			count, err = flattenConfig(config)
+		} else {
+			count, err = flattenEntry(config, args[0], args[1])
+		}
+		if err != nil {
+			return err
+		}
+
+		if count == 0 {
+			fmt.Printf("No file references found in '%s'. Nothing to flatten.\n", resolvedKubeconfigPath)
+			return nil
+		}
+
+		if err := saveKubeconfig(config, resolvedKubeconfigPath); err != nil {
+			return fmt.Errorf("error saving kubeconfig to '%s' after flattening: %w", resolvedKubeconfigPath, err)
+		}
+		fmt.Printf("Flattened %d file reference(s) in '%s'.\n", count, resolvedKubeconfigPath)
+		return nil
+	},
+}
+
+// unflattenCmd represents the unflatten command
+var unflattenCmd = &cobra.Command{
+	Use:   "unflatten [(cluster|user) <name>] [--dir <directory>]",
+	Short: "Move inline certificate, key and token data into files",
+	Long: `Write the contents of certificate-authority-data, client-certificate-data,
+client-key-data and token into files with 0600 permissions and replace them
+with references to those files. This is the inverse of the flatten command.
+
+Without arguments every cluster and user is unflattened. With a type and a
+name, only that cluster or user is unflattened.
+
+--dir specifies the directory for the generated files (default is a 'certs'
+directory next to the kubeconfig file).`,
+	Args:              flattenArgs,
+	ValidArgsFunction: completeTypeThenName([]string{"cluster", "user"}),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir := unflattenDir
+		if dir == "" {
+			dir = filepath.Join(filepath.Dir(resolvedKubeconfigPath), "certs")
+		}
+		dir, err := homedir.Expand(dir)
+		if err != nil {
+			return fmt.Errorf("error expanding directory path '%s': %w", unflattenDir, err)
+		}
+		dir, err = filepath.Abs(dir)
+		if err != nil {
+			return fmt.Errorf("error resolving directory path '%s': %w", dir, err)
+		}
+
+		config, err := loadKubeconfig(resolvedKubeconfigPath)
+		if err != nil {
+			return fmt.Errorf("error loading kubeconfig from '%s': %w", resolvedKubeconfigPath, err)
+		}
+
+		// Files written before a failure are removed again, so that a failed
+		// run leaves neither the kubeconfig nor the directory changed.
+		files := &secretFiles{}
+		count := 0
+		if len(args) == 0 {
+			count, err = unflattenConfig(config, dir, files)
+		} else {
+			count, err = unflattenEntry(config, args[0], args[1], dir, files)
+		}
+		if err != nil {
+			files.removeCreated()
+			return err
+		}
+
+		if count == 0 {
+			fmt.Printf("No inline data found in '%s'. Nothing to unflatten.\n", resolvedKubeconfigPath)
+			return nil
+		}
+
+		if err := saveKubeconfig(config, resolvedKubeconfigPath); err != nil {
+			files.removeCreated()
+			return fmt.Errorf("error saving kubeconfig to '%s' after unflattening: %w", resolvedKubeconfigPath, err)
+		}
+		fmt.Printf("Moved %d inline value(s) from '%s' into '%s'.\n", count, resolvedKubeconfigPath, dir)
+		return nil
+	},
+}
+
+// flattenArgs accepts either no arguments or a (cluster|user) type followed by a name.
+func flattenArgs(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 && len(args) != 2 {
+		return fmt.Errorf("accepts either no arguments or (cluster|user) <name>, received %d", len(args))
+	}
+	return nil
+}
+
+// flattenConfig flattens every cluster and user in the config and returns the
+// number of file references that were inlined.
+func flattenConfig(config *api.Config) (int, error) {
+	count := 0
+	for name, cluster := range config.Clusters {
+		n, err := flattenCluster(cluster)
+		if err != nil {
+			return count, fmt.Errorf("error flattening cluster '%s': %w", name, err)
+		}
+		count += n
+	}
+	for name, authInfo := range config.AuthInfos {
+		n, err := flattenAuthInfo(authInfo)
+		if err != nil {
+			return count, fmt.Errorf("error flattening user '%s': %w", name, err)
+		}
+		count += n
+	}
+	return count, nil
+}
+
+// flattenEntry flattens a single cluster or user.
+func flattenEntry(config *api.Config, itemType, itemName string) (int, error) {
+	switch itemType {
+	case "cluster":
+		cluster, ok := config.Clusters[itemName]
+		if !ok {
+			return 0, fmt.Errorf("cluster '%s' not found in '%s'", itemName, resolvedKubeconfigPath)
+		}
+		n, err := flattenCluster(cluster)
+		if err != nil {
+			return 0, fmt.Errorf("error flattening cluster '%s': %w", itemName, err)
+		}
+		return n, nil
+	case "user":
+		authInfo, ok := config.AuthInfos[itemName]
+		if !ok {
+			return 0, fmt.Errorf("user '%s' not found in '%s'", itemName, resolvedKubeconfigPath)
+		}
+		n, err := flattenAuthInfo(authInfo)
+		if err != nil {
+			return 0, fmt.Errorf("error flattening user '%s': %w", itemName, err)
+		}
+		return n, nil
+	default:
+		return 0, fmt.Errorf("invalid type '%s'. Must be one of: cluster, user", itemType)
+	}
+}
+
+// flattenCluster inlines the certificate-authority file of a cluster.
+func flattenCluster(cluster *api.Cluster) (int, error) {
+	return flattenFile(&cluster.CertificateAuthority, &cluster.CertificateAuthorityData, cluster.LocationOfOrigin)
+}
+
+// flattenAuthInfo inlines the client certificate, client key and token files of a user.
+func flattenAuthInfo(authInfo *api.AuthInfo) (int, error) {
+	count := 0
+	n, err := flattenFile(&authInfo.ClientCertificate, &authInfo.ClientCertificateData, authInfo.LocationOfOrigin)
+	if err != nil {
+		return count, err
+	}
+	count += n
+	n, err = flattenFile(&authInfo.ClientKey, &authInfo.ClientKeyData, authInfo.LocationOfOrigin)
+	if err != nil {
+		return count, err
+	}
+	count += n
+
+	if authInfo.TokenFile != "" {
+		if authInfo.Token != "" {
+			return count, fmt.Errorf("both token and tokenFile are set")
+		}
+		content, err := os.ReadFile(resolveReference(authInfo.TokenFile, authInfo.LocationOfOrigin))
+		if err != nil {
+			return count, fmt.Errorf("failed to read token file: %w", err)
+		}
+		authInfo.Token = strings.TrimSpace(string(content))
+		authInfo.TokenFile = ""
+		count++
+	}
+	return count, nil
+}
+
+// flattenFile reads the file at *path into *data and clears *path.
+func flattenFile(path *string, data *[]byte, locationOfOrigin string) (int, error) {
+	if *path == "" {
+		return 0, nil
+	}
+	if len(*data) > 0 {
+		return 0, fmt.Errorf("both '%s' and its inline data are set", *path)
+	}
+	content, err := os.ReadFile(resolveReference(*path, locationOfOrigin))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read '%s': %w", *path, err)
+	}
+	*data = content
+	*path = ""
+	return 1, nil
+}
+
+// resolveReference resolves a file reference relative to the kubeconfig it was loaded from.
+func resolveReference(path, locationOfOrigin string) string {
+	if filepath.IsAbs(path) || locationOfOrigin == "" {
+		return path
+	}
+	return filepath.Join(filepath.Dir(locationOfOrigin), path)
+}
+
+// unflattenConfig moves the inline data of every cluster and user into files in dir
+// and returns the number of values that were moved.
+func unflattenConfig(config *api.Config, dir string, files *secretFiles) (int, error) {
+	count := 0
+	for _, name := range sortedKeys(config.Clusters) {
+		n, err := unflattenCluster(name, config.Clusters[name], dir, files)
+		if err != nil {
+			return count, fmt.Errorf("error unflattening cluster '%s': %w", name, err)
+		}
+		count += n
+	}
+	for _, name := range sortedKeys(config.AuthInfos) {
+		n, err := unflattenAuthInfo(name, config.AuthInfos[name], dir, files)
+		if err != nil {
+			return count, fmt.Errorf("error unflattening user '%s': %w", name, err)
+		}
+		count += n
+	}
+	return count, nil
+}
+
+// unflattenEntry moves the inline data of a single cluster or user into files in dir.
+func unflattenEntry(config *api.Config, itemType, itemName, dir string, files *secretFiles) (int, error) {
+	switch itemType {
+	case "cluster":
+		cluster, ok := config.Clusters[itemName]
+		if !ok {
+			return 0, fmt.Errorf("cluster '%s' not found in '%s'", itemName, resolvedKubeconfigPath)
+		}
+		n, err := unflattenCluster(itemName, cluster, dir, files)
+		if err != nil {
+			return 0, fmt.Errorf("error unflattening cluster '%s': %w", itemName, err)
+		}
+		return n, nil
+	case "user":
+		authInfo, ok := config.AuthInfos[itemName]
+		if !ok {
+			return 0, fmt.Errorf("user '%s' not found in '%s'", itemName, resolvedKubeconfigPath)
+		}
+		n, err := unflattenAuthInfo(itemName, authInfo, dir, files)
+		if err != nil {
+			return 0, fmt.Errorf("error unflattening user '%s': %w", itemName, err)
+		}
+		return n, nil
+	default:
+		return 0, fmt.Errorf("invalid type '%s'. Must be one of: cluster, user", itemType)
+	}
+}
+
+// unflattenCluster writes the certificate-authority-data of a cluster into a file.
+func unflattenCluster(name string, cluster *api.Cluster, dir string, files *secretFiles) (int, error) {
+	fileName := "cluster-" + safeFileName(name) + "-ca.crt"
+	return unflattenData(&cluster.CertificateAuthority, &cluster.CertificateAuthorityData, filepath.Join(dir, fileName), files)
+}
+
+// unflattenAuthInfo writes the client certificate, client key and token of a user into files.
+func unflattenAuthInfo(name string, authInfo *api.AuthInfo, dir string, files *secretFiles) (int, error) {
+	prefix := filepath.Join(dir, "user-"+safeFileName(name))
+	count := 0
+	n, err := unflattenData(&authInfo.ClientCertificate, &authInfo.ClientCertificateData, prefix+".crt", files)
+	if err != nil {
+		return count, err
+	}
+	count += n
+	n, err = unflattenData(&authInfo.ClientKey, &authInfo.ClientKeyData, prefix+".key", files)
+	if err != nil {
+		return count, err
+	}
+	count += n
+
+	if authInfo.Token != "" {
+		if authInfo.TokenFile != "" {
+			return count, fmt.Errorf("both token and tokenFile are set")
+		}
+		if err := files.write(prefix+".token", []byte(authInfo.Token)); err != nil {
+			return count, err
+		}
+		authInfo.TokenFile = prefix + ".token"
+		authInfo.Token = ""
+		count++
+	}
+	return count, nil
+}
+
+// safeFileName returns the part of a file name derived from an item name.
+// Names with unsafe characters get a short hash suffix, so that names such as
+// 'a/b' and 'a:b' do not share a file.
+func safeFileName(name string) string {
+	safe := unsafeFileNameChars.ReplaceAllString(name, "_")
+	if safe == name {
+		return name
+	}
+	sum := sha256.Sum256([]byte(name))
+	return safe + "-" + hex.EncodeToString(sum[:4])
+}
+
+// unflattenData writes *data into filePath, points *path at it and clears *data.
+func unflattenData(path *string, data *[]byte, filePath string, files *secretFiles) (int, error) {
+	if len(*data) == 0 {
+		return 0, nil
+	}
+	if *path != "" {
+		return 0, fmt.Errorf("both '%s' and its inline data are set", *path)
+	}
+	if err := files.write(filePath, *data); err != nil {
+		return 0, err
+	}
+	*path = filePath
+	*data = nil
+	return 1, nil
+}
+
+// secretFiles records the files created by an unflatten run, so that they can
+// be removed again if the run fails.
+type secretFiles struct {
+	created []string
+}
+
+// write writes content to filePath with owner-only permissions, creating the
+// parent directory with owner-only permissions if needed. An existing file is
+// only accepted if it already holds that content, so that two entries never
+// end up sharing, or overwriting, each other's secret.
+func (f *secretFiles) write(filePath string, content []byte) error {
+	existing, err := os.ReadFile(filePath)
+	if err == nil && !bytes.Equal(existing, content) {
+		return fmt.Errorf("'%s' already exists with different content; remove it or choose another --dir", filePath)
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read '%s': %w", filePath, err)
+	}
+	if os.IsNotExist(err) {
+		f.created = append(f.created, filePath)
+	}
+	dir := filepath.Dir(filePath)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create directory '%s': %w", dir, err)
+	}
+	if err := os.WriteFile(filePath, content, 0600); err != nil {
+		return fmt.Errorf("failed to write '%s': %w", filePath, err)
+	}
+	// os.WriteFile does not change the mode of an existing file.
+	if err := os.Chmod(filePath, 0600); err != nil {
+		return fmt.Errorf("failed to set permissions on '%s': %w", filePath, err)
+	}
+	return nil
+}
+
+// removeCreated removes the files created so far. Files that already existed
+// with the same content are left in place.
+func (f *secretFiles) removeCreated() {
+	for _, filePath := range f.created {
+		if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "Warning: failed to remove '%s': %v\n", filePath, err)
+		}
+	}
+	f.created = nil
+}
+
+func init() {
+	unflattenCmd.Flags().StringVar(&unflattenDir, "dir", "", "Directory for the generated files (default is 'certs' next to the kubeconfig)")
+	rootCmd.AddCommand(flattenCmd)
+	rootCmd.AddCommand(unflattenCmd)
+}
@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func TestFlattenCommand(t *testing.T) {
+	// Helper to create a kubeconfig that references files relative to itself.
+	createKubeconfigForFlatten := func(tempDir string) string {
+		for name, content := range map[string]string{
+			"ca.crt":     "ca-data",
+			"client.crt": "client-cert-data",
+			"client.key": "client-key-data",
+			"token":      "file-token\n",
+		} {
+			err := ioutil.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644)
+			assert.NoError(t, err)
+		}
+
+		kubeconfigPath := filepath.Join(tempDir, "config")
+		err := ioutil.WriteFile(kubeconfigPath, []byte(`
+apiVersion: v1
+clusters:
+- cluster:
+    server: https://cluster1
+    certificate-authority: ca.crt
+  name: cluster1
+contexts:
+- context:
+    cluster: cluster1
+    user: user1
+  name: context1
+current-context: context1
+kind: Config
+preferences: {}
+users:
+- name: user1
+  user:
+    client-certificate: client.crt
+    client-key: client.key
+- name: user2
+  user:
+    tokenFile: token
+`), 0644)
+		assert.NoError(t, err)
+		return kubeconfigPath
+	}
+
+	// Test flattening the whole file.
+	t.Run("flatten all", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-flatten-all-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		kubeconfigPath := createKubeconfigForFlatten(tempDir)
+
+		output := executeCommandC(t, "flatten", "--kubeconfig", kubeconfigPath)
+		expectedOutput := "Flattened 4 file reference(s) in '" + kubeconfigPath + "'."
+		assert.Equal(t, expectedOutput, output)
+
+		config, err := clientcmd.LoadFromFile(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.Empty(t, config.Clusters["cluster1"].CertificateAuthority)
+		assert.Equal(t, []byte("ca-data"), config.Clusters["cluster1"].CertificateAuthorityData)
+		assert.Empty(t, config.AuthInfos["user1"].ClientCertificate)
+		assert.Equal(t, []byte("client-cert-data"), config.AuthInfos["user1"].ClientCertificateData)
+		assert.Equal(t, []byte("client-key-data"), config.AuthInfos["user1"].ClientKeyData)
+		assert.Empty(t, config.AuthInfos["user2"].TokenFile)
+		assert.Equal(t, "file-token", config.AuthInfos["user2"].Token)
+	})
+
+	// Test flattening a single entry.
+	t.Run("flatten single user", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-flatten-user-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		kubeconfigPath := createKubeconfigForFlatten(tempDir)
+
+		output := executeCommandC(t, "flatten", "user", "user2", "--kubeconfig", kubeconfigPath)
+		expectedOutput := "Flattened 1 file reference(s) in '" + kubeconfigPath + "'."
+		assert.Equal(t, expectedOutput, output)
+
+		config, err := clientcmd.LoadFromFile(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.Equal(t, "file-token", config.AuthInfos["user2"].Token)
+		assert.Equal(t, "ca.crt", config.Clusters["cluster1"].CertificateAuthority)
+		assert.Equal(t, "client.crt", config.AuthInfos["user1"].ClientCertificate)
+	})
+
+	// Test flattening a non-existent entry.
+	t.Run("flatten non-existent cluster", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-flatten-nonexistent-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		kubeconfigPath := createKubeconfigForFlatten(tempDir)
+
+		output := executeCommandC(t, "flatten", "cluster", "missing", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Error: cluster 'missing' not found in '"+kubeconfigPath+"'")
+	})
+
+	// Test that unflatten reverses flatten and writes owner-only files.
+	t.Run("unflatten all", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-unflatten-all-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		kubeconfigPath := createKubeconfigForFlatten(tempDir)
+		certsDir := filepath.Join(tempDir, "certs")
+
+		executeCommandC(t, "flatten", "--kubeconfig", kubeconfigPath)
+		output := executeCommandC(t, "unflatten", "--dir", certsDir, "--kubeconfig", kubeconfigPath)
+		expectedOutput := "Moved 4 inline value(s) from '" + kubeconfigPath + "' into '" + certsDir + "'."
+		assert.Equal(t, expectedOutput, output)
+
+		config, err := clientcmd.LoadFromFile(kubeconfigPath)
+		assert.NoError(t, err)
+		caPath := config.Clusters["cluster1"].CertificateAuthority
+		assert.Equal(t, filepath.Join(certsDir, "cluster-cluster1-ca.crt"), caPath)
+		assert.Empty(t, config.Clusters["cluster1"].CertificateAuthorityData)
+		assert.Equal(t, filepath.Join(certsDir, "user-user1.key"), config.AuthInfos["user1"].ClientKey)
+		assert.Empty(t, config.AuthInfos["user1"].ClientKeyData)
+		assert.Equal(t, filepath.Join(certsDir, "user-user2.token"), config.AuthInfos["user2"].TokenFile)
+		assert.Empty(t, config.AuthInfos["user2"].Token)
+
+		content, err := ioutil.ReadFile(caPath)
+		assert.NoError(t, err)
+		assert.Equal(t, "ca-data", string(content))
+		info, err := os.Stat(config.AuthInfos["user2"].TokenFile)
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	})
+
+	// Test unflatten on a kubeconfig without inline data.
+	t.Run("unflatten nothing", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-unflatten-nothing-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		kubeconfigPath := createKubeconfigForFlatten(tempDir)
+
+		output := executeCommandC(t, "unflatten", "--kubeconfig", kubeconfigPath)
+		expectedOutput := "No inline data found in '" + kubeconfigPath + "'. Nothing to unflatten."
+		assert.Equal(t, expectedOutput, output)
+	})
+
+	// Test that names differing only in unsafe characters get distinct files.
+	t.Run("unflatten colliding names", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-unflatten-collide-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		kubeconfigPath := filepath.Join(tempDir, "config")
+		err = ioutil.WriteFile(kubeconfigPath, []byte(`
+apiVersion: v1
+kind: Config
+users:
+- name: a/b
+  user:
+    token: token-slash
+- name: a:b
+  user:
+    token: token-colon
+`), 0600)
+		assert.NoError(t, err)
+
+		output := executeCommandC(t, "unflatten", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Moved 2 inline value(s)")
+
+		config, err := clientcmd.LoadFromFile(kubeconfigPath)
+		assert.NoError(t, err)
+		slash, colon := config.AuthInfos["a/b"].TokenFile, config.AuthInfos["a:b"].TokenFile
+		assert.NotEqual(t, slash, colon)
+		content, err := ioutil.ReadFile(slash)
+		assert.NoError(t, err)
+		assert.Equal(t, "token-slash", string(content))
+		content, err = ioutil.ReadFile(colon)
+		assert.NoError(t, err)
+		assert.Equal(t, "token-colon", string(content))
+	})
+
+	// Test that an existing file with different content is not overwritten.
+	t.Run("unflatten existing file", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-unflatten-existing-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		kubeconfigPath := createKubeconfigForFlatten(tempDir)
+		certsDir := filepath.Join(tempDir, "certs")
+		assert.NoError(t, os.MkdirAll(certsDir, 0700))
+		existingPath := filepath.Join(certsDir, "user-user2.token")
+		assert.NoError(t, ioutil.WriteFile(existingPath, []byte("other-token"), 0600))
+
+		executeCommandC(t, "flatten", "--kubeconfig", kubeconfigPath)
+		output := executeCommandC(t, "unflatten", "user", "user2", "--dir", certsDir, "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "'"+existingPath+"' already exists with different content")
+
+		content, err := ioutil.ReadFile(existingPath)
+		assert.NoError(t, err)
+		assert.Equal(t, "other-token", string(content))
+		config, err := clientcmd.LoadFromFile(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.Equal(t, "file-token", config.AuthInfos["user2"].Token)
+
+		// Files written for other entries before the conflict are removed again.
+		output = executeCommandC(t, "unflatten", "--dir", certsDir, "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "'"+existingPath+"' already exists with different content")
+		files, err := ioutil.ReadDir(certsDir)
+		assert.NoError(t, err)
+		assert.Len(t, files, 1)
+		assert.Equal(t, "user-user2.token", files[0].Name())
+	})
+}
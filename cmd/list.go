@@ -2,9 +2,17 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 	"sort"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+var (
+	listTags   []string // Flag for the tags selecting the listed contexts
+	listOutput string   // Flag for the output format
 )
 
 // listCmd represents the list command
@@ -17,10 +25,25 @@ The first argument specifies the type of item to list:
   cluster    List all cluster names.
   user       List all user names.
   context    List all context names.
-  all        List all clusters, users and contexts.`,
-	Args: cobra.ExactArgs(1), // Requires exactly one argument which is the type
+  all        List all clusters, users and contexts.
+
+--tag <key>=<value> only lists the contexts carrying that tag (see 'kedit tag');
+it can be repeated to require several tags. --output wide (or -o wide) shows
+the cluster, user, tags and note of each context.`,
+	Args:      cobra.ExactArgs(1), // Requires exactly one argument which is the type
+	ValidArgs: []string{"cluster", "user", "context", "all"},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		listType := args[0] // Will be "cluster", "user", "context", or "all"
+		if listOutput != "" && listOutput != "wide" {
+			return fmt.Errorf("invalid output format '%s'. Must be 'wide'", listOutput)
+		}
+		if (listType == "cluster" || listType == "user") && len(listTags) > 0 {
+			return fmt.Errorf("flag --tag only applies to contexts")
+		}
+		tagSelectors, err := parseTagSelectors(listTags)
+		if err != nil {
+			return err
+		}
 
 		config, err := loadKubeconfig(resolvedKubeconfigPath)
 		if err != nil {
@@ -57,19 +80,7 @@ The first argument specifies the type of item to list:
 				fmt.Printf("- %s\n", name)
 			}
 		case "context":
-			if len(config.Contexts) == 0 {
-				fmt.Println("No contexts found.")
-				return nil
-			}
-			names := make([]string, 0, len(config.Contexts))
-			for name := range config.Contexts {
-				names = append(names, name)
-			}
-			sort.Strings(names)
-			fmt.Println("Contexts:")
-			for _, name := range names {
-				fmt.Printf("- %s\n", name)
-			}
+			printContexts(config, tagSelectors)
 		case "all":
 			if len(config.Clusters) == 0 {
 				fmt.Println("No clusters found.")
@@ -99,19 +110,7 @@ The first argument specifies the type of item to list:
 				}
 			}
 
-			if len(config.Contexts) == 0 {
-				fmt.Println("No contexts found.")
-			} else {
-				contextNames := make([]string, 0, len(config.Contexts))
-				for name := range config.Contexts {
-					contextNames = append(contextNames, name)
-				}
-				sort.Strings(contextNames)
-				fmt.Println("Contexts:")
-				for _, name := range contextNames {
-					fmt.Printf("- %s\n", name)
-				}
-			}
+			printContexts(config, tagSelectors)
 		default:
 			// This case should ideally not be reached if Cobra validates based on Use line,
 			// but good for robustness and if Use line is manually typed wrong by user.
@@ -121,6 +120,44 @@ The first argument specifies the type of item to list:
 	},
 }
 
+// printContexts prints the contexts carrying all of the tags in selectors, as a
+// table with their tags and notes if --output is wide.
+func printContexts(config *api.Config, selectors map[string]string) {
+	var names []string
+	for _, name := range sortedKeys(config.Contexts) {
+		if matchesTags(config.Contexts[name], selectors) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		if len(selectors) > 0 {
+			fmt.Printf("No contexts tagged %s found.\n", formatTags(selectors))
+		} else {
+			fmt.Println("No contexts found.")
+		}
+		return
+	}
+
+	fmt.Println("Contexts:")
+	if listOutput != "wide" {
+		for _, name := range names {
+			fmt.Printf("- %s\n", name)
+		}
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tCLUSTER\tUSER\tTAGS\tNOTE")
+	for _, name := range names {
+		context := config.Contexts[name]
+		// Unreadable metadata is shown as empty rather than hiding the context.
+		metadata, _ := readContextMetadata(context)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", name, context.Cluster, context.AuthInfo, formatTags(metadata.Tags), metadata.Note)
+	}
+	w.Flush()
+}
+
 func init() {
+	listCmd.Flags().StringArrayVar(&listTags, "tag", nil, "Only list contexts with this <key>=<value> tag (repeatable)")
+	listCmd.Flags().StringVarP(&listOutput, "output", "o", "", "Output format: wide shows the cluster, user, tags and note of contexts")
 	rootCmd.AddCommand(listCmd)
 }
@@ -1,12 +1,12 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
 	"github.com/mitchellh/go-homedir"
 	"github.com/spf13/cobra"
-	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/clientcmd/api"
 )
 
@@ -26,11 +26,13 @@ from another kubeconfig file into the current target kubeconfig file.
 --name (or -n) allows renaming the context and its associated cluster and user upon merging.
 
 If an item (context, cluster, or user) with the same name already exists
-in the target kubeconfig, it will be overwritten by the item from the source.`,
-	Args: cobra.ExactArgs(1), // Requires context_name
-	RunE: func(cmd *cobra.Command, args []string) error {
-		contextToMerge := args[0]
+in the target kubeconfig, it will be overwritten by the item from the source.
 
+On a terminal, <context_name> can be omitted to pick it from the source file
+with a fuzzy search.`,
+	Args:              exactArgsOrPick(1, 0), // Requires context_name; it can be picked on a terminal
+	ValidArgsFunction: completeSourceContexts,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		if sourceKubeconfigPath == "" {
 			return fmt.Errorf("flag --from <source_kubeconfig_path> is required for the merge command")
 		}
@@ -45,11 +47,26 @@ in the target kubeconfig, it will be overwritten by the item from the source.`,
 		if _, statErr := os.Stat(expandedSourcePath); os.IsNotExist(statErr) {
 			return fmt.Errorf("source kubeconfig file '%s' not found", expandedSourcePath)
 		}
-		sourceConfig, err := clientcmd.LoadFromFile(expandedSourcePath) // Use direct LoadFromFile for source
+		// loadKubeconfig also opens encrypted kubeconfigs and sealed fields.
+		sourceConfig, err := loadKubeconfig(expandedSourcePath)
 		if err != nil {
 			return fmt.Errorf("failed to load source kubeconfig from '%s': %w", expandedSourcePath, err)
 		}
 
+		var contextToMerge string
+		if len(args) == 1 {
+			contextToMerge = args[0]
+		} else {
+			contextToMerge, err = pickName(sourceConfig, "context", expandedSourcePath)
+			if errors.Is(err, errPickerCancelled) {
+				fmt.Println("No context selected. Nothing to merge.")
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+		}
+
 		// Find the context in the source config
 		sourceContext, contextExists := sourceConfig.Contexts[contextToMerge]
 		if !contextExists {
@@ -107,6 +124,13 @@ in the target kubeconfig, it will be overwritten by the item from the source.`,
 		}
 		// loadKubeconfig ensures maps are initialized in targetConfig
 
+		// Tags and notes of an overwritten context are kept
+		if existingContext, ok := targetConfig.Contexts[finalContextName]; ok {
+			if err := mergeContextMetadata(existingContext, sourceContext); err != nil {
+				return fmt.Errorf("context '%s' in target kubeconfig '%s': %w", finalContextName, resolvedKubeconfigPath, err)
+			}
+		}
+
 		// Add/Overwrite context, cluster, and user to the target config
 		targetConfig.Contexts[finalContextName] = sourceContext
 		targetConfig.Clusters[finalClusterName] = sourceCluster
@@ -289,4 +289,41 @@ users:
 		assert.Equal(t, "renamed", config.Contexts["renamed"].Cluster)
 		assert.Equal(t, "renamed", config.Contexts["renamed"].AuthInfo)
 	})
-}
\ No newline at end of file
+
+	// Test merging from an encrypted kubeconfig, e.g. on a shared drive.
+	t.Run("merge from encrypted kubeconfig", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-merge-encrypted-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		t.Setenv("KEDIT_PASSPHRASE", "shared passphrase")
+
+		targetKubeconfigPath := createTargetKubeconfig(tempDir)
+		encrypted, err := encryptKubeconfig([]byte(`
+apiVersion: v1
+clusters:
+- cluster:
+    server: https://new-cluster
+  name: new-cluster
+contexts:
+- context:
+    cluster: new-cluster
+    user: new-user
+  name: new-context
+kind: Config
+users:
+- name: new-user
+  user:
+    token: new-token
+`), []byte("shared passphrase"))
+		assert.NoError(t, err)
+		sourceKubeconfigPath := createSourceKubeconfig(tempDir, string(encrypted))
+
+		output := executeCommandC(t, "merge", "new-context", "--from", sourceKubeconfigPath, "--kubeconfig", targetKubeconfigPath)
+		assert.Contains(t, output, "Successfully merged context 'new-context'")
+
+		config, err := clientcmd.LoadFromFile(targetKubeconfigPath)
+		assert.NoError(t, err)
+		assert.Equal(t, "https://new-cluster", config.Clusters["new-cluster"].Server)
+		assert.Equal(t, "new-token", config.AuthInfos["new-user"].Token)
+	})
+}
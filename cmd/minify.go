@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+var (
+	minifyOutput string // Flag for the file to write the minified kubeconfig to
+	minifyYes    bool   // Flag to skip the confirmation
+)
+
+// minifyCmd represents the minify command
+var minifyCmd = &cobra.Command{
+	Use:   "minify <pattern>... [-o <file>] [--yes]",
+	Short: "Reduce a kubeconfig to the contexts matching a pattern",
+	Long: `Rewrite the target kubeconfig so that it only contains the contexts matching
+one of the given glob patterns ('*' matches any characters, '?' a single one),
+and the clusters and users they reference. Everything else is removed.
+
+If the current-context is removed, it is set to the first remaining context.
+
+The entries to be removed are listed first, and kedit asks for confirmation
+before writing; --yes skips the question, e.g. in CI. Without --yes, kedit
+fails if no answer can be read, such as when stdin is not a terminal.
+
+--output (or -o) writes the minified kubeconfig to another file instead of
+rewriting the target in place. Relative file references are then resolved to
+absolute paths, so that the new file works from any location. If the target is
+encrypted or has sealed fields, so is the new file.`,
+	Args:              cobra.MinimumNArgs(1), // Requires at least one pattern
+	ValidArgsFunction: completeContextNames,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadKubeconfig(resolvedKubeconfigPath)
+		if err != nil {
+			return fmt.Errorf("error loading kubeconfig from '%s': %w", resolvedKubeconfigPath, err)
+		}
+
+		sourcePath, err := absolutePath(resolvedKubeconfigPath)
+		if err != nil {
+			return err
+		}
+		outputPath := sourcePath
+		if minifyOutput != "" {
+			if outputPath, err = absolutePath(minifyOutput); err != nil {
+				return err
+			}
+		}
+
+		candidates, kept := minifyCandidates(config, args)
+		if len(kept) == 0 {
+			return fmt.Errorf("no contexts in '%s' match %s", resolvedKubeconfigPath, strings.Join(args, ", "))
+		}
+		newCurrentContext := config.CurrentContext
+		if !containsString(kept, config.CurrentContext) {
+			newCurrentContext = kept[0]
+		}
+
+		if len(candidates) == 0 && newCurrentContext == config.CurrentContext && outputPath == sourcePath {
+			fmt.Printf("Nothing to remove: '%s' only contains the selected contexts.\n", resolvedKubeconfigPath)
+			return nil
+		}
+
+		fmt.Printf("Keeping %d context(s): %s\n", len(kept), strings.Join(kept, ", "))
+		if len(candidates) > 0 {
+			fmt.Printf("The following %d entries will be removed:\n", len(candidates))
+			for _, candidate := range candidates {
+				fmt.Printf("  %s '%s' (%s)\n", candidate.kind, candidate.name, candidate.reason)
+			}
+		}
+		if newCurrentContext != config.CurrentContext {
+			fmt.Printf("The current-context will change from '%s' to '%s'.\n", config.CurrentContext, newCurrentContext)
+		}
+
+		if !minifyYes {
+			question := fmt.Sprintf("Write the minified kubeconfig to '%s'?", outputPath)
+			if _, err := os.Stat(outputPath); err == nil {
+				question = fmt.Sprintf("Overwrite '%s'?", outputPath)
+			}
+			ok, err := confirm(question, "pass --yes to minify without asking")
+			if err != nil {
+				return err
+			}
+			if !ok {
+				fmt.Println("No changes made.")
+				return nil
+			}
+		}
+
+		applyPruneCandidates(config, candidates)
+		config.CurrentContext = newCurrentContext
+		if outputPath != sourcePath {
+			// File references are relative to the source kubeconfig.
+			if err := clientcmd.ResolveLocalPaths(config); err != nil {
+				return fmt.Errorf("error resolving file references: %w", err)
+			}
+		}
+		// The output file is protected like the source: it stays encrypted and
+		// its fields stay sealed.
+		if err := saveKubeconfigFrom(config, outputPath, sourcePath); err != nil {
+			return fmt.Errorf("error saving minified kubeconfig to '%s': %w", outputPath, err)
+		}
+		fmt.Printf("Wrote minified kubeconfig with %d context(s) to '%s'; removed %d entries.\n", len(kept), outputPath, len(candidates))
+		return nil
+	},
+}
+
+// minifyCandidates returns the entries to remove so that config only contains
+// the contexts matching one of the patterns, and the names of those contexts.
+func minifyCandidates(config *api.Config, patterns []string) ([]pruneCandidate, []string) {
+	var candidates []pruneCandidate
+	var kept []string
+	remaining := api.NewConfig()
+	for _, name := range sortedKeys(config.Contexts) {
+		selected := false
+		for _, pattern := range patterns {
+			if matchGlob(pattern, name) {
+				selected = true
+				break
+			}
+		}
+		if selected {
+			kept = append(kept, name)
+			remaining.Contexts[name] = config.Contexts[name]
+		} else {
+			candidates = append(candidates, pruneCandidate{kind: "context", name: name, reason: "not selected"})
+		}
+	}
+
+	// Clusters and users are removed if none of the kept contexts references them.
+	remaining.Clusters = config.Clusters
+	remaining.AuthInfos = config.AuthInfos
+	for _, candidate := range unreferencedEntryCandidates(remaining) {
+		candidate.reason = "not referenced by a selected context"
+		candidates = append(candidates, candidate)
+	}
+	return candidates, kept
+}
+
+func init() {
+	minifyCmd.Flags().StringVarP(&minifyOutput, "output", "o", "", "Write the minified kubeconfig to this file instead of the target")
+	minifyCmd.Flags().BoolVarP(&minifyYes, "yes", "y", false, "Overwrite without asking")
+	rootCmd.AddCommand(minifyCmd)
+}
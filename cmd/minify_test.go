@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMinifyCommand(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "kedit-test-minify-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	kubeconfigPath := filepath.Join(tempDir, "config")
+	err = ioutil.WriteFile(kubeconfigPath, []byte(`
+apiVersion: v1
+clusters:
+- cluster:
+    server: https://ci
+    certificate-authority: ca.crt
+  name: ci
+- cluster:
+    server: https://prod
+  name: prod
+contexts:
+- context:
+    cluster: ci
+    user: ci-user
+  name: ci-eu
+- context:
+    cluster: ci
+    user: ci-user
+  name: ci-us
+- context:
+    cluster: prod
+    user: admin
+  name: prod
+current-context: prod
+kind: Config
+preferences: {}
+users:
+- name: ci-user
+  user:
+    token: ci-token
+- name: admin
+  user:
+    token: admin-token
+`), 0644)
+	assert.NoError(t, err)
+
+	// Test that the removal list is shown and nothing changes without confirmation.
+	t.Run("minify declined", func(t *testing.T) {
+		output := executeCommandWithInput(t, "n\n", "minify", "ci-*", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Keeping 2 context(s): ci-eu, ci-us")
+		assert.Contains(t, output, "The following 3 entries will be removed:\n"+
+			"  context 'prod' (not selected)\n"+
+			"  cluster 'prod' (not referenced by a selected context)\n"+
+			"  user 'admin' (not referenced by a selected context)")
+		assert.Contains(t, output, "The current-context will change from 'prod' to 'ci-eu'.")
+		assert.Contains(t, output, "No changes made.")
+
+		// Without --yes and without an answer, as in CI, minify fails.
+		output = executeCommandC(t, "minify", "ci-*", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Error: no answer given on stdin; pass --yes to minify without asking")
+
+		config, err := loadKubeconfig(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.Len(t, config.Contexts, 3)
+	})
+
+	// Test writing to a new file, which resolves relative file references.
+	t.Run("minify to new file", func(t *testing.T) {
+		outputPath := filepath.Join(tempDir, "ci", "config")
+		output := executeCommandWithInput(t, "y\n", "minify", "ci-us", "--kubeconfig", kubeconfigPath, "-o", outputPath)
+		assert.Contains(t, output, "Write the minified kubeconfig to '"+outputPath+"'? [y/N]: ")
+		assert.Contains(t, output, "Wrote minified kubeconfig with 1 context(s) to '"+outputPath+"'; removed 4 entries.")
+
+		config, err := loadKubeconfig(outputPath)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"ci-us"}, sortedKeys(config.Contexts))
+		assert.Equal(t, []string{"ci"}, sortedKeys(config.Clusters))
+		assert.Equal(t, []string{"ci-user"}, sortedKeys(config.AuthInfos))
+		assert.Equal(t, "ci-us", config.CurrentContext)
+		assert.Equal(t, filepath.Join(tempDir, "ca.crt"), config.Clusters["ci"].CertificateAuthority)
+	})
+
+	// Test rewriting the target in place with --yes.
+	t.Run("minify in place", func(t *testing.T) {
+		output := executeCommandC(t, "minify", "ci-*", "--kubeconfig", kubeconfigPath, "--yes")
+		assert.Contains(t, output, "removed 3 entries.")
+
+		config, err := loadKubeconfig(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"ci-eu", "ci-us"}, sortedKeys(config.Contexts))
+		assert.Equal(t, "ci-eu", config.CurrentContext)
+		assert.Equal(t, "ca.crt", config.Clusters["ci"].CertificateAuthority)
+
+		output = executeCommandC(t, "minify", "ci-*", "--kubeconfig", kubeconfigPath)
+		assert.Equal(t, "Nothing to remove: '"+kubeconfigPath+"' only contains the selected contexts.", output)
+
+		output = executeCommandC(t, "minify", "dev-*", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Error: no contexts in '"+kubeconfigPath+"' match dev-*")
+	})
+}
+
+func TestMinifyKeepsProtection(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "kedit-test-minify-protected-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+	t.Setenv("KEDIT_PASSPHRASE", "s3cret passphrase")
+	t.Setenv("KEDIT_FIELD_KEY_FILE", filepath.Join(tempDir, "field.key"))
+
+	content := []byte(`
+apiVersion: v1
+clusters:
+- cluster:
+    server: https://ci
+  name: ci
+- cluster:
+    server: https://prod
+  name: prod
+contexts:
+- context:
+    cluster: ci
+    user: ci-user
+  name: ci
+- context:
+    cluster: prod
+    user: admin
+  name: prod
+current-context: ci
+kind: Config
+users:
+- name: ci-user
+  user:
+    token: ci-token
+- name: admin
+  user:
+    token: admin-token
+`)
+
+	// Test that the output of an encrypted kubeconfig is encrypted.
+	t.Run("minify encrypted", func(t *testing.T) {
+		sourcePath := filepath.Join(tempDir, "encrypted")
+		assert.NoError(t, ioutil.WriteFile(sourcePath, content, 0600))
+		executeCommandC(t, "encrypt", sourcePath)
+
+		outputPath := filepath.Join(tempDir, "encrypted-ci")
+		executeCommandC(t, "minify", "ci", "--kubeconfig", sourcePath, "-o", outputPath, "--yes")
+		output, err := ioutil.ReadFile(outputPath)
+		assert.NoError(t, err)
+		assert.True(t, isEncryptedKubeconfig(output))
+
+		config, err := loadKubeconfig(outputPath)
+		assert.NoError(t, err)
+		assert.Equal(t, "ci-token", config.AuthInfos["ci-user"].Token)
+	})
+
+	// Test that the output of a kubeconfig with sealed fields has them sealed.
+	t.Run("minify sealed", func(t *testing.T) {
+		sourcePath := filepath.Join(tempDir, "sealed")
+		assert.NoError(t, ioutil.WriteFile(sourcePath, content, 0600))
+		executeCommandC(t, "seal-fields", "--generate-key", "--kubeconfig", sourcePath)
+
+		outputPath := filepath.Join(tempDir, "sealed-ci")
+		executeCommandC(t, "minify", "ci", "--kubeconfig", sourcePath, "-o", outputPath, "--yes")
+		output, err := ioutil.ReadFile(outputPath)
+		assert.NoError(t, err)
+		assert.NotContains(t, string(output), "ci-token")
+		assert.Contains(t, string(output), "token: ENC[AES256_GCM,data:")
+
+		config, err := loadKubeconfig(outputPath)
+		assert.NoError(t, err)
+		assert.Equal(t, "ci-token", config.AuthInfos["ci-user"].Token)
+		assert.NotContains(t, config.AuthInfos, "admin")
+	})
+}
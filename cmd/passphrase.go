@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/term"
+)
+
+// scrypt parameters for new envelopes. They are stored in the envelope, so
+// they can be raised later without breaking existing files.
+const (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// errWrongPassphrase is returned when an envelope cannot be opened.
+var errWrongPassphrase = errors.New("wrong passphrase or corrupted data")
+
+// passphraseEnvelope holds data encrypted with AES-256-GCM under a key derived
+// from a passphrase with scrypt.
+type passphraseEnvelope struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	N          int    `json:"n"`
+	R          int    `json:"r"`
+	P          int    `json:"p"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// sealWithPassphrase encrypts plaintext with a key derived from passphrase.
+// purpose is authenticated along with the data, so that an envelope made for
+// one purpose cannot be passed off as another.
+func sealWithPassphrase(plaintext, passphrase []byte, purpose string) (*passphraseEnvelope, error) {
+	envelope := &passphraseEnvelope{Version: 1, KDF: "scrypt", N: scryptN, R: scryptR, P: scryptP, Salt: make([]byte, 16)}
+	if _, err := rand.Read(envelope.Salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	aead, err := envelope.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	envelope.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(envelope.Nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	envelope.Ciphertext = aead.Seal(nil, envelope.Nonce, plaintext, []byte(purpose))
+	return envelope, nil
+}
+
+// open decrypts the envelope. It returns errWrongPassphrase if the passphrase
+// is wrong or the data was modified.
+func (e *passphraseEnvelope) open(passphrase []byte, purpose string) ([]byte, error) {
+	if e.Version != 1 || e.KDF != "scrypt" {
+		return nil, fmt.Errorf("unsupported encryption version %d (%s)", e.Version, e.KDF)
+	}
+	aead, err := e.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if len(e.Nonce) != aead.NonceSize() {
+		return nil, errWrongPassphrase
+	}
+	plaintext, err := aead.Open(nil, e.Nonce, e.Ciphertext, []byte(purpose))
+	if err != nil {
+		return nil, errWrongPassphrase
+	}
+	return plaintext, nil
+}
+
+// aead derives the key from passphrase and returns the AES-GCM cipher.
+func (e *passphraseEnvelope) aead(passphrase []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(passphrase, e.Salt, e.N, e.R, e.P, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// readPassphrase returns the passphrase from the environment variable envVar
+// or, on an interactive terminal, asks for it. With confirm, a passphrase
+// typed on the terminal has to be entered twice.
+func readPassphrase(envVar, prompt string, confirm bool) ([]byte, error) {
+	if value := os.Getenv(envVar); value != "" {
+		return []byte(value), nil
+	}
+	if !isInteractiveTerminal() {
+		return nil, fmt.Errorf("no passphrase given: set $%s or run kedit in a terminal", envVar)
+	}
+	passphrase, err := readHiddenLine(prompt + ": ")
+	if err != nil {
+		return nil, err
+	}
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("the passphrase must not be empty")
+	}
+	if confirm {
+		again, err := readHiddenLine("Repeat " + prompt + ": ")
+		if err != nil {
+			return nil, err
+		}
+		if string(again) != string(passphrase) {
+			return nil, fmt.Errorf("the passphrases do not match")
+		}
+	}
+	return passphrase, nil
+}
+
+// readHiddenLine reads a line from the terminal without echoing it.
+func readHiddenLine(prompt string) ([]byte, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	line, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	return line, nil
+}
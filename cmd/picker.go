@@ -0,0 +1,299 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// pickerVisibleItems is the maximum number of matches shown below the query line.
+const pickerVisibleItems = 10
+
+// errPickerCancelled is returned when the user leaves the picker without choosing.
+var errPickerCancelled = errors.New("selection cancelled")
+
+// isInteractiveTerminal reports whether a picker can be shown. The picker reads
+// from stdin and draws on stderr, so stdout can still be redirected.
+var isInteractiveTerminal = func() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stderr.Fd()))
+}
+
+// exactArgsOrPick accepts exactly n arguments. On an interactive terminal it
+// also accepts pickable arguments, leaving the missing name to be picked.
+func exactArgsOrPick(n, pickable int) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if len(args) == pickable && isInteractiveTerminal() {
+			return nil
+		}
+		return cobra.ExactArgs(n)(cmd, args)
+	}
+}
+
+// pickerItem is an entry of the picker: a name and secondary text shown next to it.
+type pickerItem struct {
+	name   string
+	detail string
+}
+
+// pickerItemsFor returns the names of the given item type with a short
+// description of each: cluster and namespace for contexts, server for
+// clusters and the contexts using it for users.
+func pickerItemsFor(config *api.Config, itemType string) ([]pickerItem, error) {
+	var items []pickerItem
+	switch itemType {
+	case "context":
+		for _, name := range sortedKeys(config.Contexts) {
+			context := config.Contexts[name]
+			detail := context.Cluster
+			if context.Namespace != "" {
+				detail += "/" + context.Namespace
+			}
+			items = append(items, pickerItem{name: name, detail: detail})
+		}
+	case "cluster":
+		for _, name := range sortedKeys(config.Clusters) {
+			items = append(items, pickerItem{name: name, detail: config.Clusters[name].Server})
+		}
+	case "user":
+		for _, name := range sortedKeys(config.AuthInfos) {
+			items = append(items, pickerItem{name: name, detail: strings.Join(contextsReferencing(config, "user", name), ", ")})
+		}
+	default:
+		return nil, fmt.Errorf("invalid type '%s'. Must be one of: cluster, user, context", itemType)
+	}
+	return items, nil
+}
+
+// pickName lets the user pick the name of a cluster, user or context from config.
+func pickName(config *api.Config, itemType, source string) (string, error) {
+	items, err := pickerItemsFor(config, itemType)
+	if err != nil {
+		return "", err
+	}
+	if len(items) == 0 {
+		return "", fmt.Errorf("no %ss found in '%s'", itemType, source)
+	}
+	return runPicker(fmt.Sprintf("Select %s", itemType), items, os.Stdin, os.Stderr)
+}
+
+// promptLine asks for a line of text on stderr and reads it from stdin.
+func promptLine(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", errPickerCancelled
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// confirm asks a yes/no question and reads the answer from stdin. If no
+// answer can be read at all, as when stdin is empty and not a terminal, it
+// returns an error mentioning hint instead of silently assuming no.
+func confirm(question, hint string) (bool, error) {
+	fmt.Printf("%s [y/N]: ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Println()
+		return false, fmt.Errorf("no answer given on stdin; %s", hint)
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
+// runPicker shows the picker inline below the cursor until an item is chosen.
+func runPicker(title string, items []pickerItem, in *os.File, out *os.File) (string, error) {
+	oldState, err := term.MakeRaw(int(in.Fd()))
+	if err != nil {
+		return "", fmt.Errorf("failed to put terminal in raw mode: %w", err)
+	}
+	defer term.Restore(int(in.Fd()), oldState)
+
+	model := newPickerModel(title, items)
+	width, _, err := term.GetSize(int(out.Fd()))
+	if err != nil {
+		width = tuiDefaultWidth
+	}
+
+	drawn := 0
+	buf := make([]byte, 256)
+	for {
+		// Move back to the first line of the previous drawing and clear it.
+		if drawn > 1 {
+			fmt.Fprintf(out, "\x1b[%dA", drawn-1)
+		}
+		lines := model.render(width)
+		fmt.Fprint(out, "\r\x1b[J"+strings.Join(lines, "\r\n"))
+		drawn = len(lines)
+
+		n, err := in.Read(buf)
+		if err != nil {
+			return "", fmt.Errorf("failed to read from terminal: %w", err)
+		}
+		for _, key := range parseKeys(buf[:n]) {
+			if !model.handleKey(key) {
+				continue
+			}
+			if drawn > 1 {
+				fmt.Fprintf(out, "\x1b[%dA", drawn-1)
+			}
+			fmt.Fprint(out, "\r\x1b[J")
+			if model.chosen == "" {
+				return "", errPickerCancelled
+			}
+			return model.chosen, nil
+		}
+	}
+}
+
+// pickerModel holds the state of the picker, independent of the terminal.
+type pickerModel struct {
+	title   string
+	items   []pickerItem
+	query   string
+	matches []pickerItem
+	cursor  int
+	chosen  string
+}
+
+// newPickerModel returns a picker over items with an empty query.
+func newPickerModel(title string, items []pickerItem) *pickerModel {
+	m := &pickerModel{title: title, items: items}
+	m.filter()
+	return m
+}
+
+// filter recomputes the matches for the current query.
+func (m *pickerModel) filter() {
+	m.matches = filterPickerItems(m.items, m.query)
+	m.cursor = 0
+}
+
+// handleKey applies a key press and reports whether the picker is done.
+func (m *pickerModel) handleKey(key string) bool {
+	switch key {
+	case "esc", "ctrl-c":
+		return true
+	case "enter":
+		if len(m.matches) == 0 {
+			return false
+		}
+		m.chosen = m.matches[m.cursor].name
+		return true
+	case "up":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down":
+		if m.cursor < len(m.matches)-1 {
+			m.cursor++
+		}
+	case "backspace":
+		if m.query != "" {
+			_, size := utf8.DecodeLastRuneInString(m.query)
+			m.query = m.query[:len(m.query)-size]
+			m.filter()
+		}
+	default:
+		if utf8.RuneCountInString(key) == 1 {
+			m.query += key
+			m.filter()
+		}
+	}
+	return false
+}
+
+// render returns the lines of the picker: the query followed by the visible matches.
+func (m *pickerModel) render(width int) []string {
+	lines := []string{fitText(fmt.Sprintf("%s (%d/%d)> %s", m.title, len(m.matches), len(m.items), m.query), width)}
+	offset := 0
+	if m.cursor >= pickerVisibleItems {
+		offset = m.cursor - pickerVisibleItems + 1
+	}
+	for i := offset; i < len(m.matches) && i < offset+pickerVisibleItems; i++ {
+		item := m.matches[i]
+		line := "  " + item.name
+		if item.detail != "" {
+			line += "  (" + item.detail + ")"
+		}
+		line = fitText(line, width)
+		if i == m.cursor {
+			line = ansiReverse + ">" + line[1:] + ansiReset
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// filterPickerItems returns the items whose name or detail fuzzy-matches the
+// query, best matches first. An empty query matches every item.
+func filterPickerItems(items []pickerItem, query string) []pickerItem {
+	type scoredItem struct {
+		item  pickerItem
+		score int
+	}
+	var scored []scoredItem
+	for _, item := range items {
+		score, ok := fuzzyScore(query, item.name)
+		if detailScore, detailOK := fuzzyScore(query, item.detail); detailOK && (!ok || detailScore/2 > score) {
+			// Matches in the secondary text count for less than matches in the name.
+			score, ok = detailScore/2, true
+		}
+		if ok {
+			scored = append(scored, scoredItem{item, score})
+		}
+	}
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].score > scored[j].score
+	})
+
+	matches := make([]pickerItem, len(scored))
+	for i, s := range scored {
+		matches[i] = s.item
+	}
+	return matches
+}
+
+// fuzzyScore reports whether the characters of query appear in text in order,
+// ignoring case, and scores the match. Consecutive characters and characters
+// at the start of a word score higher; shorter texts win ties.
+func fuzzyScore(query, text string) (int, bool) {
+	if query == "" {
+		return 0, true
+	}
+	queryRunes := []rune(strings.ToLower(query))
+	textRunes := []rune(strings.ToLower(text))
+
+	score, q := 0, 0
+	previousMatch := -2
+	for i, r := range textRunes {
+		if q == len(queryRunes) {
+			break
+		}
+		if r != queryRunes[q] {
+			continue
+		}
+		score++
+		if previousMatch == i-1 {
+			score += 5
+		}
+		if i == 0 || !unicode.IsLetter(textRunes[i-1]) && !unicode.IsDigit(textRunes[i-1]) {
+			score += 3
+		}
+		previousMatch = i
+		q++
+	}
+	if q < len(queryRunes) {
+		return 0, false
+	}
+	return score*100 - len(textRunes), true
+}
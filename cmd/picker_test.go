@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestPickerModel(t *testing.T) {
+	items := []pickerItem{
+		{name: "dev-eu", detail: "cluster-eu/default"},
+		{name: "prod-eu", detail: "cluster-eu/payments"},
+		{name: "prod-us", detail: "cluster-us"},
+	}
+
+	// Test that typing narrows the matches and enter picks the first one.
+	t.Run("picker filter and choose", func(t *testing.T) {
+		m := newPickerModel("Select context", items)
+		assert.Len(t, m.matches, 3)
+
+		for _, key := range []string{"p", "u", "s"} {
+			assert.False(t, m.handleKey(key))
+		}
+		assert.Equal(t, []pickerItem{{name: "prod-us", detail: "cluster-us"}}, m.matches)
+		assert.Equal(t, "Select context (1/3)> pus", m.render(80)[0])
+
+		assert.True(t, m.handleKey("enter"))
+		assert.Equal(t, "prod-us", m.chosen)
+	})
+
+	// Test moving the selection, editing the query and cancelling.
+	t.Run("picker navigation and cancel", func(t *testing.T) {
+		m := newPickerModel("Select context", items)
+		m.handleKey("down")
+		m.handleKey("down")
+		m.handleKey("down")
+		assert.Equal(t, 2, m.cursor)
+		assert.Contains(t, m.render(80)[3], "> prod-us  (cluster-us)")
+
+		m.handleKey("x")
+		assert.Empty(t, m.matches)
+		assert.False(t, m.handleKey("enter"))
+		m.handleKey("backspace")
+		assert.Len(t, m.matches, 3)
+
+		assert.True(t, m.handleKey("esc"))
+		assert.Empty(t, m.chosen)
+	})
+
+	// Test that the secondary text is searched too.
+	t.Run("picker matches detail", func(t *testing.T) {
+		m := newPickerModel("Select context", items)
+		for _, key := range []string{"p", "a", "y"} {
+			m.handleKey(key)
+		}
+		assert.Equal(t, "prod-eu", m.matches[0].name)
+	})
+}
+
+func TestFuzzyScore(t *testing.T) {
+	_, ok := fuzzyScore("pe", "prod-eu")
+	assert.True(t, ok)
+	_, ok = fuzzyScore("ep", "prod-eu")
+	assert.False(t, ok)
+
+	// Consecutive and word-start matches rank higher than scattered ones.
+	consecutive, _ := fuzzyScore("eu", "prod-eu")
+	scattered, _ := fuzzyScore("eu", "dev-us")
+	assert.Greater(t, consecutive, scattered)
+	assert.Equal(t, []pickerItem{{name: "prod-eu"}, {name: "dev-us"}},
+		filterPickerItems([]pickerItem{{name: "dev-us"}, {name: "prod-eu"}}, "EU"))
+}
+
+func TestPickerItemsFor(t *testing.T) {
+	config := api.NewConfig()
+	config.Clusters["cluster1"] = &api.Cluster{Server: "https://cluster1"}
+	config.AuthInfos["user1"] = api.NewAuthInfo()
+	config.Contexts["context1"] = &api.Context{Cluster: "cluster1", AuthInfo: "user1", Namespace: "payments"}
+
+	items, err := pickerItemsFor(config, "context")
+	assert.NoError(t, err)
+	assert.Equal(t, []pickerItem{{name: "context1", detail: "cluster1/payments"}}, items)
+
+	items, err = pickerItemsFor(config, "cluster")
+	assert.NoError(t, err)
+	assert.Equal(t, []pickerItem{{name: "cluster1", detail: "https://cluster1"}}, items)
+
+	items, err = pickerItemsFor(config, "user")
+	assert.NoError(t, err)
+	assert.Equal(t, []pickerItem{{name: "user1", detail: "context1"}}, items)
+
+	_, err = pickerItemsFor(config, "namespace")
+	assert.EqualError(t, err, "invalid type 'namespace'. Must be one of: cluster, user, context")
+}
+
+func TestExactArgsOrPick(t *testing.T) {
+	args := exactArgsOrPick(2, 1)
+
+	// Without a terminal the strict argument count is kept.
+	output := executeCommandC(t, "delete", "context")
+	assert.Contains(t, output, "Error: accepts 2 arg(s), received 1")
+	assert.Error(t, args(deleteCmd, []string{"context"}))
+
+	oldIsInteractiveTerminal := isInteractiveTerminal
+	defer func() { isInteractiveTerminal = oldIsInteractiveTerminal }()
+	isInteractiveTerminal = func() bool { return true }
+
+	assert.NoError(t, args(deleteCmd, []string{"context"}))
+	assert.NoError(t, args(deleteCmd, []string{"context", "context1"}))
+	assert.Error(t, args(deleteCmd, []string{}))
+}
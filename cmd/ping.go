@@ -0,0 +1,318 @@
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+var (
+	pingAll              bool          // Flag to ping every context
+	pingTimeout          time.Duration // Flag for the per-context timeout
+	pingParallel         int           // Flag for the number of contexts checked concurrently
+	pingAttempts         int           // Flag for the number of attempts per context
+	pingRetryDelay       time.Duration // Flag for the pause between attempts
+	pingPruneUnreachable bool          // Flag to offer removing unreachable contexts
+	pingYes              bool          // Flag to remove unreachable contexts without asking
+)
+
+// Ping statuses, from healthy to failed.
+const (
+	pingStatusOK          = "ok"
+	pingStatusNotReady    = "not-ready"
+	pingStatusAuthError   = "auth-error"
+	pingStatusTLSError    = "tls-error"
+	pingStatusTimeout     = "timeout"
+	pingStatusUnreachable = "unreachable"
+	pingStatusConfigError = "config-error"
+	pingStatusError       = "error"
+)
+
+// pingResult is the outcome of checking a single context.
+type pingResult struct {
+	context string
+	status  string
+	latency time.Duration
+	version string
+	err     error
+}
+
+// pingCmd represents the ping command
+var pingCmd = &cobra.Command{
+	Use:   "ping [context_name...|--all]",
+	Short: "Check connectivity and health of contexts",
+	Long: `Check whether the API servers behind one or more contexts are reachable and healthy.
+
+For each context kedit builds a client from the kubeconfig (including TLS
+settings and credentials), calls /version and /readyz, and reports the
+reachability, latency and server version. Failures are classified as
+tls-error, auth-error, timeout, unreachable, config-error or error.
+
+Without arguments, the current-context is checked. --all checks every context.
+Contexts are checked in parallel, each bounded by --timeout.
+
+--prune-unreachable offers to remove contexts that could not be reached in
+any of the --attempts made (network errors and timeouts), --retry-delay
+apart. It needs at least 2 attempts and makes 3 unless --attempts is given.
+Use --yes to remove them without asking.`,
+	ValidArgsFunction: completeContextNames,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if pingAll && len(args) > 0 {
+			return fmt.Errorf("flag --all cannot be combined with context names")
+		}
+		if pingParallel < 1 || pingAttempts < 1 {
+			return fmt.Errorf("flags --parallel and --attempts must be at least 1")
+		}
+		if pingPruneUnreachable {
+			// A single failed request is not enough to call a context unreachable.
+			if !cmd.Flags().Changed("attempts") {
+				pingAttempts = 3
+			} else if pingAttempts < 2 {
+				return fmt.Errorf("flag --prune-unreachable needs --attempts of at least 2")
+			}
+		}
+
+		config, err := loadKubeconfig(resolvedKubeconfigPath)
+		if err != nil {
+			return fmt.Errorf("error loading kubeconfig from '%s': %w", resolvedKubeconfigPath, err)
+		}
+
+		contextNames := args
+		if pingAll {
+			contextNames = sortedKeys(config.Contexts)
+		} else if len(contextNames) == 0 {
+			if config.CurrentContext == "" {
+				return fmt.Errorf("no current-context set in '%s'; specify context names or --all", resolvedKubeconfigPath)
+			}
+			contextNames = []string{config.CurrentContext}
+		}
+		for _, name := range contextNames {
+			if _, ok := config.Contexts[name]; !ok {
+				return fmt.Errorf("context '%s' not found in '%s'", name, resolvedKubeconfigPath)
+			}
+		}
+		if len(contextNames) == 0 {
+			fmt.Printf("No contexts found in '%s'.\n", resolvedKubeconfigPath)
+			return nil
+		}
+
+		results := pingContexts(config, contextNames)
+		printPingResults(results)
+
+		if !pingPruneUnreachable {
+			return nil
+		}
+		var candidates []pruneCandidate
+		for _, result := range results {
+			if result.status == pingStatusUnreachable || result.status == pingStatusTimeout {
+				candidates = append(candidates, pruneCandidate{kind: "context", name: result.context, reason: result.status})
+			}
+		}
+		if len(candidates) == 0 {
+			fmt.Println("All contexts are reachable. Nothing to prune.")
+			return nil
+		}
+		if !pingYes {
+			ok, err := confirm(fmt.Sprintf("Remove %d unreachable context(s)?", len(candidates)), "pass --yes to remove unreachable contexts")
+			if err != nil {
+				return err
+			}
+			if !ok {
+				fmt.Println("No contexts removed.")
+				return nil
+			}
+		}
+		currentContext := config.CurrentContext
+		applyPruneCandidates(config, candidates)
+		if err := saveKubeconfig(config, resolvedKubeconfigPath); err != nil {
+			return fmt.Errorf("error saving kubeconfig to '%s' after removing unreachable contexts: %w", resolvedKubeconfigPath, err)
+		}
+		reportPruneCandidates(candidates, currentContext)
+		fmt.Printf("Removed %d unreachable context(s) from '%s'. Run 'kedit prune' to remove clusters and users they no longer reference.\n", len(candidates), resolvedKubeconfigPath)
+		return nil
+	},
+}
+
+// pingContexts checks the given contexts concurrently and returns the results in the same order.
+func pingContexts(config *api.Config, contextNames []string) []pingResult {
+	// Resolve file references against the kubeconfig they were loaded from,
+	// as clientcmd's loading rules would.
+	resolved := config.DeepCopy()
+	resolveErr := clientcmd.ResolveLocalPaths(resolved)
+
+	results := make([]pingResult, len(contextNames))
+	semaphore := make(chan struct{}, pingParallel)
+	var wg sync.WaitGroup
+	for i, name := range contextNames {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			if resolveErr != nil {
+				results[i] = pingResult{context: name, status: pingStatusConfigError, err: resolveErr}
+				return
+			}
+			for attempt := 0; attempt < pingAttempts; attempt++ {
+				if attempt > 0 {
+					time.Sleep(pingRetryDelay)
+				}
+				results[i] = pingContext(resolved, name)
+				if results[i].status != pingStatusUnreachable && results[i].status != pingStatusTimeout {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// pingContext checks a single context by calling /version and /readyz.
+func pingContext(config *api.Config, contextName string) pingResult {
+	result := pingResult{context: contextName}
+
+	restConfig, err := clientcmd.NewNonInteractiveClientConfig(*config, contextName, &clientcmd.ConfigOverrides{}, nil).ClientConfig()
+	if err != nil {
+		result.status, result.err = pingStatusConfigError, err
+		return result
+	}
+	restConfig.Timeout = pingTimeout
+	client, err := rest.HTTPClientFor(restConfig)
+	if err != nil {
+		result.status, result.err = pingStatusConfigError, err
+		return result
+	}
+	baseURL := strings.TrimSuffix(restConfig.Host, "/")
+
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+
+	start := time.Now()
+	body, statusCode, err := pingGet(ctx, client, baseURL+"/version")
+	result.latency = time.Since(start)
+	if err != nil {
+		result.status, result.err = classifyPingError(ctx, err), err
+		return result
+	}
+	if statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden {
+		result.status, result.err = pingStatusAuthError, fmt.Errorf("server returned %d %s", statusCode, http.StatusText(statusCode))
+		return result
+	}
+	if statusCode != http.StatusOK {
+		result.status, result.err = pingStatusError, fmt.Errorf("/version returned %d %s", statusCode, http.StatusText(statusCode))
+		return result
+	}
+	var info struct {
+		GitVersion string `json:"gitVersion"`
+	}
+	if err := json.Unmarshal(body, &info); err != nil {
+		result.status, result.err = pingStatusError, fmt.Errorf("failed to decode /version response: %w", err)
+		return result
+	}
+	result.version = info.GitVersion
+
+	body, statusCode, err = pingGet(ctx, client, baseURL+"/readyz")
+	if err != nil {
+		result.status, result.err = classifyPingError(ctx, err), err
+		return result
+	}
+	if statusCode != http.StatusOK {
+		result.status, result.err = pingStatusNotReady, fmt.Errorf("/readyz returned %d: %s", statusCode, strings.TrimSpace(string(body)))
+		return result
+	}
+	result.status = pingStatusOK
+	return result
+}
+
+// pingGet performs a GET request and returns the response body and status code.
+func pingGet(ctx context.Context, client *http.Client, url string) ([]byte, int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, 0, err
+	}
+	return body, resp.StatusCode, nil
+}
+
+// classifyPingError maps a request error to a ping status.
+func classifyPingError(ctx context.Context, err error) string {
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var certificateInvalidErr x509.CertificateInvalidError
+	var tlsVerificationErr *tls.CertificateVerificationError
+	var tlsRecordHeaderErr tls.RecordHeaderError
+	var netErr net.Error
+	var opErr *net.OpError
+
+	switch {
+	case errors.As(err, &unknownAuthorityErr), errors.As(err, &hostnameErr),
+		errors.As(err, &certificateInvalidErr), errors.As(err, &tlsVerificationErr),
+		errors.As(err, &tlsRecordHeaderErr):
+		return pingStatusTLSError
+	case errors.Is(err, context.DeadlineExceeded) || ctx.Err() != nil:
+		return pingStatusTimeout
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return pingStatusTimeout
+	case errors.As(err, &opErr):
+		return pingStatusUnreachable
+	default:
+		return pingStatusError
+	}
+}
+
+// printPingResults prints the results as a table.
+func printPingResults(results []pingResult) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CONTEXT\tSTATUS\tLATENCY\tVERSION\tERROR")
+	for _, result := range results {
+		latency, version, errMsg := "-", "-", ""
+		if result.latency > 0 && result.status != pingStatusConfigError {
+			latency = result.latency.Round(time.Millisecond).String()
+		}
+		if result.version != "" {
+			version = result.version
+		}
+		if result.err != nil {
+			errMsg = result.err.Error()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", result.context, result.status, latency, version, errMsg)
+	}
+	w.Flush()
+}
+
+func init() {
+	pingCmd.Flags().BoolVar(&pingAll, "all", false, "Ping every context")
+	pingCmd.Flags().DurationVar(&pingTimeout, "timeout", 5*time.Second, "Timeout per context")
+	pingCmd.Flags().IntVar(&pingParallel, "parallel", 10, "Number of contexts to check concurrently")
+	pingCmd.Flags().IntVar(&pingAttempts, "attempts", 1, "Number of attempts before a context is considered unreachable (3 with --prune-unreachable)")
+	pingCmd.Flags().DurationVar(&pingRetryDelay, "retry-delay", 2*time.Second, "Pause between attempts")
+	pingCmd.Flags().BoolVar(&pingPruneUnreachable, "prune-unreachable", false, "Offer to remove contexts that could not be reached")
+	pingCmd.Flags().BoolVarP(&pingYes, "yes", "y", false, "Remove unreachable contexts without asking")
+	rootCmd.AddCommand(pingCmd)
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestPingCommand(t *testing.T) {
+	// A fake API server that requires the bearer token "good-token".
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer good-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/version":
+			fmt.Fprint(w, `{"gitVersion":"v1.30.2"}`)
+		case "/readyz":
+			fmt.Fprint(w, "ok")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	server.Config.ErrorLog = log.New(io.Discard, "", 0) // Silence expected TLS handshake errors.
+	server.StartTLS()
+	defer server.Close()
+	caData := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	// An address on which nothing is listening.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	closedURL := "https://" + listener.Addr().String()
+	listener.Close()
+
+	// Helper to create a kubeconfig with healthy and broken contexts.
+	createKubeconfigForPing := func(tempDir string) string {
+		config := api.NewConfig()
+		config.CurrentContext = "ok-context"
+		config.Clusters["good-cluster"] = &api.Cluster{Server: server.URL, CertificateAuthorityData: caData}
+		config.Clusters["untrusted-cluster"] = &api.Cluster{Server: server.URL}
+		config.Clusters["closed-cluster"] = &api.Cluster{Server: closedURL, InsecureSkipTLSVerify: true}
+		config.AuthInfos["good-user"] = &api.AuthInfo{Token: "good-token"}
+		config.AuthInfos["bad-user"] = &api.AuthInfo{Token: "bad-token"}
+		config.Contexts["ok-context"] = &api.Context{Cluster: "good-cluster", AuthInfo: "good-user"}
+		config.Contexts["auth-context"] = &api.Context{Cluster: "good-cluster", AuthInfo: "bad-user"}
+		config.Contexts["tls-context"] = &api.Context{Cluster: "untrusted-cluster", AuthInfo: "good-user"}
+		config.Contexts["closed-context"] = &api.Context{Cluster: "closed-cluster", AuthInfo: "good-user"}
+
+		kubeconfigPath := filepath.Join(tempDir, "config")
+		assert.NoError(t, saveKubeconfig(config, kubeconfigPath))
+		return kubeconfigPath
+	}
+
+	// Test pinging the current context.
+	t.Run("ping current context", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-ping-current-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		kubeconfigPath := createKubeconfigForPing(tempDir)
+
+		output := executeCommandC(t, "ping", "--kubeconfig", kubeconfigPath)
+		assert.Regexp(t, `ok-context\s+ok\s+\S+\s+v1\.30\.2`, output)
+		assert.NotContains(t, output, "auth-context")
+	})
+
+	// Test pinging every context and classifying failures.
+	t.Run("ping all", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-ping-all-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		kubeconfigPath := createKubeconfigForPing(tempDir)
+
+		output := executeCommandC(t, "ping", "--all", "--kubeconfig", kubeconfigPath)
+		assert.Regexp(t, `auth-context\s+auth-error`, output)
+		assert.Regexp(t, `closed-context\s+unreachable`, output)
+		assert.Regexp(t, `ok-context\s+ok`, output)
+		assert.Regexp(t, `tls-context\s+tls-error`, output)
+	})
+
+	// Test removing unreachable contexts.
+	t.Run("ping prune unreachable", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-ping-prune-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		kubeconfigPath := createKubeconfigForPing(tempDir)
+
+		output := executeCommandC(t, "ping", "ok-context", "closed-context", "--prune-unreachable", "--retry-delay", "10ms", "--yes", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Pruned context 'closed-context' (unreachable).")
+		assert.Contains(t, output, "Removed 1 unreachable context(s) from '"+kubeconfigPath+"'.")
+
+		config, err := clientcmd.LoadFromFile(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.Nil(t, config.Contexts["closed-context"])
+		assert.NotNil(t, config.Contexts["ok-context"])
+	})
+
+	// Test declining the removal of unreachable contexts.
+	t.Run("ping prune unreachable declined", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-ping-decline-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		kubeconfigPath := createKubeconfigForPing(tempDir)
+
+		output := executeCommandWithInput(t, "n\n", "ping", "closed-context", "--prune-unreachable", "--retry-delay", "10ms", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Remove 1 unreachable context(s)? [y/N]: No contexts removed.")
+
+		// Without an answer nothing is removed either, but the command fails.
+		output = executeCommandC(t, "ping", "closed-context", "--prune-unreachable", "--retry-delay", "10ms", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Error: no answer given on stdin; pass --yes to remove unreachable contexts")
+
+		// A single attempt is not enough to remove a context.
+		output = executeCommandC(t, "ping", "closed-context", "--prune-unreachable", "--attempts", "1", "--yes", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Error: flag --prune-unreachable needs --attempts of at least 2")
+
+		config, err := clientcmd.LoadFromFile(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.NotNil(t, config.Contexts["closed-context"])
+	})
+
+	// Test pinging a non-existent context.
+	t.Run("ping non-existent context", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-ping-nonexistent-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		kubeconfigPath := createKubeconfigForPing(tempDir)
+
+		output := executeCommandC(t, "ping", "missing", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Error: context 'missing' not found in '"+kubeconfigPath+"'")
+	})
+}
@@ -0,0 +1,268 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+
+	yaml "go.yaml.in/yaml/v3"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// preserveFormat serializes config by editing the YAML node tree of existing,
+// the current content of the file, so that comments, the order of entries and
+// untouched entries are kept. If nothing changed, existing is returned as is.
+// It returns false if existing cannot be edited this way: it is empty, JSON,
+// uses anchors or is not a YAML mapping.
+func preserveFormat(existing []byte, config *api.Config) ([]byte, bool, error) {
+	trimmed := bytes.TrimSpace(existing)
+	if len(trimmed) == 0 || trimmed[0] == '{' {
+		return nil, false, nil
+	}
+	var oldDoc yaml.Node
+	if err := yaml.Unmarshal(existing, &oldDoc); err != nil {
+		return nil, false, nil
+	}
+	if oldDoc.Kind != yaml.DocumentNode || len(oldDoc.Content) != 1 || oldDoc.Content[0].Kind != yaml.MappingNode || hasAliases(&oldDoc) {
+		return nil, false, nil
+	}
+
+	content, err := clientcmd.Write(*config)
+	if err != nil {
+		return nil, false, err
+	}
+	var newDoc yaml.Node
+	if err := yaml.Unmarshal(content, &newDoc); err != nil {
+		return nil, false, fmt.Errorf("failed to parse serialized kubeconfig: %w", err)
+	}
+	if newDoc.Kind != yaml.DocumentNode || len(newDoc.Content) != 1 {
+		return nil, false, fmt.Errorf("unexpected structure of serialized kubeconfig")
+	}
+	if yamlNodesEqual(oldDoc.Content[0], newDoc.Content[0]) {
+		return existing, true, nil
+	}
+	oldDoc.Content[0] = mergeYAMLNode(oldDoc.Content[0], newDoc.Content[0])
+
+	merged, err := encodeYAMLDocument(&oldDoc, existing)
+	if err != nil {
+		return nil, false, err
+	}
+	return merged, true, nil
+}
+
+// encodeYAMLDocument serializes doc with the indentation style of like.
+func encodeYAMLDocument(doc *yaml.Node, like []byte) ([]byte, error) {
+	indent, compact := detectYAMLIndent(like)
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(indent)
+	if compact {
+		encoder.CompactSeqIndent()
+	}
+	if err := encoder.Encode(doc); err != nil {
+		return nil, fmt.Errorf("failed to serialize kubeconfig: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to serialize kubeconfig: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// mergeYAMLNode returns the node to write for a value that was old in the
+// existing file and is now new. Unchanged values keep their old node;
+// mappings and lists of named entries (clusters, users, contexts, extensions)
+// are merged so that only the changed parts are replaced.
+func mergeYAMLNode(old, new *yaml.Node) *yaml.Node {
+	if yamlNodesEqual(old, new) {
+		return old
+	}
+	if old.Style&yaml.FlowStyle == 0 {
+		switch {
+		case old.Kind == yaml.MappingNode && new.Kind == yaml.MappingNode:
+			return mergeYAMLMapping(old, new)
+		case old.Kind == yaml.SequenceNode && new.Kind == yaml.SequenceNode && isNamedSequence(old) && isNamedSequence(new):
+			return mergeYAMLNamedSequence(old, new)
+		}
+	}
+	if old.Kind == yaml.ScalarNode && new.Kind == yaml.ScalarNode && old.Tag == new.Tag {
+		new.Style = old.Style
+	}
+	new.HeadComment, new.LineComment, new.FootComment = old.HeadComment, old.LineComment, old.FootComment
+	return new
+}
+
+// mergeYAMLMapping merges two mappings key by key. Keys keep their position in
+// old, keys only in new are appended, and keys no longer in new are removed
+// unless their value is empty (e.g. "preferences: {}").
+func mergeYAMLMapping(old, new *yaml.Node) *yaml.Node {
+	newValues := make(map[string]*yaml.Node)
+	for i := 0; i+1 < len(new.Content); i += 2 {
+		newValues[new.Content[i].Value] = new.Content[i+1]
+	}
+
+	merged := *old
+	merged.Content = nil
+	seen := make(map[string]bool)
+	for i := 0; i+1 < len(old.Content); i += 2 {
+		key, value := old.Content[i], old.Content[i+1]
+		seen[key.Value] = true
+		newValue, ok := newValues[key.Value]
+		switch {
+		case ok:
+			merged.Content = append(merged.Content, key, mergeYAMLNode(value, newValue))
+		case isEmptyYAMLNode(value):
+			merged.Content = append(merged.Content, key, value)
+		}
+	}
+	for i := 0; i+1 < len(new.Content); i += 2 {
+		key, value := new.Content[i], new.Content[i+1]
+		if !seen[key.Value] && !isEmptyYAMLNode(value) {
+			merged.Content = append(merged.Content, key, value)
+		}
+	}
+	return &merged
+}
+
+// mergeYAMLNamedSequence merges two lists of named entries by name. Entries
+// keep their position in old, new entries are appended and removed entries
+// are dropped. An entry renamed without other changes keeps its position.
+func mergeYAMLNamedSequence(old, new *yaml.Node) *yaml.Node {
+	oldNames := make(map[string]bool)
+	for _, item := range old.Content {
+		oldNames[yamlEntryName(item)] = true
+	}
+	newItems := make(map[string]*yaml.Node)
+	for _, item := range new.Content {
+		newItems[yamlEntryName(item)] = item
+	}
+
+	// Pair removed and added entries with the same content as renames.
+	renamedTo := make(map[*yaml.Node]*yaml.Node)
+	renamed := make(map[*yaml.Node]bool)
+	for _, newItem := range new.Content {
+		if oldNames[yamlEntryName(newItem)] {
+			continue
+		}
+		newFields := yamlNodeFields(newItem)
+		delete(newFields, "name")
+		for _, oldItem := range old.Content {
+			if _, ok := newItems[yamlEntryName(oldItem)]; ok || renamedTo[oldItem] != nil {
+				continue
+			}
+			oldFields := yamlNodeFields(oldItem)
+			delete(oldFields, "name")
+			if reflect.DeepEqual(oldFields, newFields) {
+				renamedTo[oldItem] = newItem
+				renamed[newItem] = true
+				break
+			}
+		}
+	}
+
+	merged := *old
+	merged.Content = nil
+	for _, item := range old.Content {
+		if newItem, ok := newItems[yamlEntryName(item)]; ok {
+			merged.Content = append(merged.Content, mergeYAMLNode(item, newItem))
+		} else if newItem, ok := renamedTo[item]; ok {
+			merged.Content = append(merged.Content, mergeYAMLNode(item, newItem))
+		}
+	}
+	for _, item := range new.Content {
+		if !oldNames[yamlEntryName(item)] && !renamed[item] {
+			merged.Content = append(merged.Content, item)
+		}
+	}
+	return &merged
+}
+
+// isNamedSequence reports whether every item of a list is a mapping with a name.
+func isNamedSequence(node *yaml.Node) bool {
+	for _, item := range node.Content {
+		if item.Kind != yaml.MappingNode || yamlEntryName(item) == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// yamlEntryName returns the value of the "name" key of a mapping, or "".
+func yamlEntryName(node *yaml.Node) string {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == "name" && node.Content[i+1].Kind == yaml.ScalarNode {
+			return node.Content[i+1].Value
+		}
+	}
+	return ""
+}
+
+// yamlNodesEqual reports whether two nodes hold the same content, ignoring
+// comments, formatting, the order of keys and empty values.
+func yamlNodesEqual(a, b *yaml.Node) bool {
+	return reflect.DeepEqual(yamlNodeFields(a), yamlNodeFields(b))
+}
+
+// isEmptyYAMLNode reports whether a node holds no content.
+func isEmptyYAMLNode(node *yaml.Node) bool {
+	return len(yamlNodeFields(node)) == 0
+}
+
+// yamlNodeFields flattens a node into field paths and values (see flattenFields).
+func yamlNodeFields(node *yaml.Node) map[string]string {
+	fields := make(map[string]string)
+	var value interface{}
+	if err := node.Decode(&value); err != nil {
+		// Undecodable nodes never compare equal to anything else.
+		fields[""] = fmt.Sprintf("%p", node)
+		return fields
+	}
+	flattenFields("", value, fields)
+	return fields
+}
+
+// hasAliases reports whether the tree contains anchors or aliases, which
+// cannot be preserved when entries are replaced.
+func hasAliases(node *yaml.Node) bool {
+	if node.Kind == yaml.AliasNode || node.Anchor != "" {
+		return true
+	}
+	for _, child := range node.Content {
+		if hasAliases(child) {
+			return true
+		}
+	}
+	return false
+}
+
+// detectYAMLIndent returns the indentation used in a YAML file, and whether
+// list items are at the same indentation as their key, as written by kubectl:
+//
+//	clusters:
+//	- cluster:
+func detectYAMLIndent(data []byte) (int, bool) {
+	indent, compact := 0, false
+	previous := ""
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		spaces := len(line) - len(trimmed)
+		if spaces > 0 && (indent == 0 || spaces < indent) {
+			indent = spaces
+		}
+		if strings.HasPrefix(trimmed, "- ") && previous != "" {
+			previousTrimmed := strings.TrimLeft(previous, " ")
+			if len(previous)-len(previousTrimmed) == spaces && !strings.HasPrefix(previousTrimmed, "- ") && strings.HasSuffix(previousTrimmed, ":") {
+				compact = true
+			}
+		}
+		previous = line
+	}
+	if indent == 0 {
+		indent = 2
+	}
+	return indent, compact
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSavePreservesFormat(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "kedit-test-preserve-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	kubeconfigPath := filepath.Join(tempDir, "config")
+	original := `# Team kubeconfig, managed in git.
+apiVersion: v1
+kind: Config
+current-context: zeta
+clusters:
+# Production first.
+- name: zeta
+  cluster:
+    server: https://zeta # behind the VPN
+- name: alpha
+  cluster:
+    server: https://alpha
+    insecure-skip-tls-verify: true
+contexts:
+- name: zeta
+  context:
+    cluster: zeta
+    user: admin
+- name: alpha
+  context:
+    cluster: alpha
+    user: admin
+users:
+- name: admin
+  user:
+    token: secret
+`
+	err = ioutil.WriteFile(kubeconfigPath, []byte(original), 0644)
+	assert.NoError(t, err)
+
+	// Test that a rename only changes the touched lines.
+	t.Run("preserve rename", func(t *testing.T) {
+		executeCommandC(t, "rename", "cluster", "alpha", "beta", "--kubeconfig", kubeconfigPath)
+
+		content, err := ioutil.ReadFile(kubeconfigPath)
+		assert.NoError(t, err)
+		expected := strings.NewReplacer(
+			"- name: alpha\n  cluster:", "- name: beta\n  cluster:",
+			"cluster: alpha\n", "cluster: beta\n",
+		).Replace(original)
+		assert.Equal(t, expected, string(content))
+	})
+
+	// Test that loading and saving an unchanged config leaves the file untouched.
+	t.Run("preserve unchanged", func(t *testing.T) {
+		before, err := ioutil.ReadFile(kubeconfigPath)
+		assert.NoError(t, err)
+		config, err := loadKubeconfig(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.NoError(t, saveKubeconfig(config, kubeconfigPath))
+		after, err := ioutil.ReadFile(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.Equal(t, string(before), string(after))
+	})
+
+	// Test that deleted entries are removed and new ones appended.
+	t.Run("preserve delete and add", func(t *testing.T) {
+		config, err := loadKubeconfig(kubeconfigPath)
+		assert.NoError(t, err)
+		delete(config.Contexts, "alpha")
+		config.Contexts["gamma"] = config.Contexts["zeta"].DeepCopy()
+		config.Contexts["gamma"].Namespace = "tools"
+		assert.NoError(t, saveKubeconfig(config, kubeconfigPath))
+
+		content, err := ioutil.ReadFile(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.Contains(t, string(content), "# Team kubeconfig, managed in git.\n")
+		assert.Contains(t, string(content), "# Production first.\n- name: zeta\n")
+		assert.Contains(t, string(content), "server: https://zeta # behind the VPN\n")
+		assert.Contains(t, string(content), "contexts:\n- name: zeta\n  context:\n    cluster: zeta\n    user: admin\n- context:\n    cluster: zeta\n    namespace: tools\n    user: admin\n  name: gamma\nusers:")
+	})
+
+	// Test that JSON kubeconfigs fall back to a full rewrite.
+	t.Run("preserve json fallback", func(t *testing.T) {
+		jsonPath := filepath.Join(tempDir, "config.json")
+		err := ioutil.WriteFile(jsonPath, []byte(`{"apiVersion": "v1", "kind": "Config", "clusters": [{"name": "c1", "cluster": {"server": "https://c1"}}]}`), 0644)
+		assert.NoError(t, err)
+
+		executeCommandC(t, "rename", "cluster", "c1", "c2", "--kubeconfig", jsonPath)
+		config, err := loadKubeconfig(jsonPath)
+		assert.NoError(t, err)
+		assert.Contains(t, config.Clusters, "c2")
+	})
+}
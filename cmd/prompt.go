@@ -0,0 +1,327 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	promptFormat  string   // Flag for the output template
+	promptColors  []string // Flag for color rules (pattern=color)
+	promptShell   string   // Flag for the shell whose prompt escaping is used
+	promptNoColor bool     // Flag to disable colors
+)
+
+// defaultPromptFormat is used when neither --format nor the kedit config file set a format.
+const defaultPromptFormat = "{{.Context}}:{{.Namespace}}"
+
+// promptColorCodes maps the supported color names to ANSI SGR codes.
+var promptColorCodes = map[string]string{
+	"black":   "30",
+	"red":     "31",
+	"green":   "32",
+	"yellow":  "33",
+	"blue":    "34",
+	"magenta": "35",
+	"cyan":    "36",
+	"white":   "37",
+	"bold":    "1",
+}
+
+// promptKubeconfig is the subset of a kubeconfig read by the prompt command.
+// Decoding only these fields is much faster than loading the full config.
+type promptKubeconfig struct {
+	CurrentContext string               `json:"current-context"`
+	Contexts       []promptContextEntry `json:"contexts"`
+}
+
+// promptContextEntry is a named context of a promptKubeconfig.
+type promptContextEntry struct {
+	Name    string `json:"name"`
+	Context struct {
+		Cluster   string `json:"cluster"`
+		User      string `json:"user"`
+		Namespace string `json:"namespace"`
+	} `json:"context"`
+}
+
+// promptInfo holds the fields available to the prompt template.
+type promptInfo struct {
+	Context   string
+	Cluster   string
+	User      string
+	Namespace string
+}
+
+// promptCmd represents the prompt command
+var promptCmd = &cobra.Command{
+	Use:   "prompt [--format <template>] [--color <pattern>=<color>]... [--shell bash|zsh|fish]",
+	Short: "Print the current context for use in a shell prompt",
+	Long: `Print the current context, formatted for a shell prompt.
+
+Only the current-context and the contexts list are decoded, so this is much
+faster than running kubectl. Without --kubeconfig, the files in $KUBECONFIG
+are honored the way kubectl does. Nothing is printed if no current-context is set.
+
+--format is a Go template with the fields .Context, .Cluster, .User and
+.Namespace (defaults to 'default'). The default format is
+'` + defaultPromptFormat + `'.
+
+--color pattern=color colors the prompt when the current context matches the
+glob pattern, e.g. --color 'prod-*=red'. The first matching rule wins. Colors
+are: black, red, green, yellow, blue, magenta, cyan, white and bold, and can be
+combined with '+', e.g. bold+red. A default format and color rules can also be
+set in the kedit config file:
+
+  prompt:
+    format: '{{.Context}}'
+    colors:
+    - pattern: prod-*
+      color: red
+
+--shell wraps the color codes so that bash and zsh compute the prompt width correctly.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		settings, err := loadKeditSettings()
+		if err != nil {
+			return err
+		}
+
+		format := promptFormat
+		if format == "" {
+			format = settings.Prompt.Format
+		}
+		if format == "" {
+			format = defaultPromptFormat
+		}
+		tmpl, err := template.New("prompt").Parse(format)
+		if err != nil {
+			return fmt.Errorf("invalid --format: %w", err)
+		}
+
+		rules, err := parsePromptColorRules(promptColors)
+		if err != nil {
+			return err
+		}
+		rules = append(rules, settings.Prompt.Colors...)
+
+		info, err := readPromptInfo(kubeconfigChainPaths())
+		if err != nil {
+			return err
+		}
+		if info == nil {
+			return nil
+		}
+
+		var text strings.Builder
+		if err := tmpl.Execute(&text, info); err != nil {
+			return fmt.Errorf("failed to render prompt: %w", err)
+		}
+
+		color := ""
+		if !promptNoColor {
+			for _, rule := range rules {
+				if matchGlob(rule.Pattern, info.Context) {
+					color = rule.Color
+					break
+				}
+			}
+		}
+		output, err := formatPrompt(text.String(), color, promptShell)
+		if err != nil {
+			return err
+		}
+		fmt.Print(output)
+		return nil
+	},
+}
+
+// kubeconfigChainPaths returns the kubeconfig files kubectl would read: the
+// --kubeconfig flag, else the files listed in $KUBECONFIG, else the default path.
+func kubeconfigChainPaths() []string {
+	if cfgFile == "" {
+		if env := os.Getenv("KUBECONFIG"); env != "" {
+			var paths []string
+			for _, path := range filepath.SplitList(env) {
+				if path != "" {
+					paths = append(paths, path)
+				}
+			}
+			return paths
+		}
+	}
+	return []string{resolvedKubeconfigPath}
+}
+
+// readPromptInfo reads the current context from the given kubeconfig files.
+// As with kubectl, the first current-context and the first definition of each
+// context win. It returns nil if no current-context is set or it does not exist.
+func readPromptInfo(paths []string) (*promptInfo, error) {
+	currentContext := ""
+	contexts := make(map[string]promptContextEntry)
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read kubeconfig '%s': %w", path, err)
+		}
+		if isEncryptedKubeconfig(content) {
+			// The prompt cannot ask for a passphrase.
+			continue
+		}
+		var kubeconfig promptKubeconfig
+		if err := yaml.Unmarshal(content, &kubeconfig); err != nil {
+			return nil, fmt.Errorf("failed to parse kubeconfig '%s': %w", path, err)
+		}
+		if currentContext == "" {
+			currentContext = kubeconfig.CurrentContext
+		}
+		for _, entry := range kubeconfig.Contexts {
+			if _, exists := contexts[entry.Name]; !exists {
+				contexts[entry.Name] = entry
+			}
+		}
+	}
+
+	entry, ok := contexts[currentContext]
+	if currentContext == "" || !ok {
+		return nil, nil
+	}
+	info := &promptInfo{
+		Context:   currentContext,
+		Cluster:   entry.Context.Cluster,
+		User:      entry.Context.User,
+		Namespace: entry.Context.Namespace,
+	}
+	if info.Namespace == "" {
+		info.Namespace = "default"
+	}
+	return info, nil
+}
+
+// parsePromptColorRules parses --color values of the form pattern=color.
+func parsePromptColorRules(values []string) ([]promptColorRule, error) {
+	var rules []promptColorRule
+	for _, value := range values {
+		index := strings.LastIndex(value, "=")
+		if index <= 0 {
+			return nil, fmt.Errorf("invalid --color '%s', expected <pattern>=<color>", value)
+		}
+		rules = append(rules, promptColorRule{Pattern: value[:index], Color: value[index+1:]})
+	}
+	return rules, nil
+}
+
+// formatPrompt colors text and escapes it for the given shell's prompt.
+func formatPrompt(text, color, shell string) (string, error) {
+	switch shell {
+	case "", "fish":
+	case "zsh":
+		// A literal '%' would start a zsh prompt escape.
+		text = strings.ReplaceAll(text, "%", "%%")
+	case "bash":
+	default:
+		return "", fmt.Errorf("invalid --shell '%s'. Must be one of: bash, zsh, fish", shell)
+	}
+	if color == "" {
+		return text, nil
+	}
+
+	var codes []string
+	for _, name := range strings.Split(color, "+") {
+		code, ok := promptColorCodes[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return "", fmt.Errorf("unknown color '%s'", name)
+		}
+		codes = append(codes, code)
+	}
+	start := "\x1b[" + strings.Join(codes, ";") + "m"
+	reset := ansiReset
+
+	// Mark the escape sequences as zero-width so the shell computes the prompt width correctly.
+	switch shell {
+	case "bash":
+		start, reset = "\x01"+start+"\x02", "\x01"+reset+"\x02"
+	case "zsh":
+		start, reset = "%{"+start+"%}", "%{"+reset+"%}"
+	}
+	return start + text + reset, nil
+}
+
+// shellInitSnippets are printed by "kedit init" to wire the prompt and completion into a shell.
+var shellInitSnippets = map[string]string{
+	"bash": `# kedit shell integration. Add to ~/.bashrc:
+#   eval "$(kedit init bash)"
+source <(kedit completion bash)
+__kedit_prompt() {
+  KEDIT_PROMPT="$(kedit prompt --shell bash 2>/dev/null)"
+}
+# Evaluating the snippet again, e.g. when ~/.bashrc is sourced again, changes nothing.
+case ";$PROMPT_COMMAND;" in
+  *";__kedit_prompt;"*) ;;
+  *) PROMPT_COMMAND="__kedit_prompt${PROMPT_COMMAND:+;$PROMPT_COMMAND}" ;;
+esac
+case "$PS1" in
+  *'$KEDIT_PROMPT'*) ;;
+  *) PS1='${KEDIT_PROMPT:+[$KEDIT_PROMPT] }'"$PS1" ;;
+esac
+`,
+	"zsh": `# kedit shell integration. Add to ~/.zshrc:
+#   eval "$(kedit init zsh)"
+source <(kedit completion zsh)
+setopt prompt_subst
+__kedit_prompt() {
+  KEDIT_PROMPT="$(kedit prompt --shell zsh 2>/dev/null)"
+}
+typeset -ag precmd_functions
+# Evaluating the snippet again, e.g. when ~/.zshrc is sourced again, changes nothing.
+(( ${precmd_functions[(I)__kedit_prompt]} )) || precmd_functions+=(__kedit_prompt)
+[[ $RPROMPT == *'${KEDIT_PROMPT}'* ]] || RPROMPT='${KEDIT_PROMPT}'"$RPROMPT"
+`,
+	"fish": `# kedit shell integration. Add to ~/.config/fish/config.fish:
+#   kedit init fish | source
+kedit completion fish | source
+function fish_right_prompt
+    kedit prompt --shell fish 2>/dev/null
+end
+`,
+}
+
+// initCmd represents the init command
+var initCmd = &cobra.Command{
+	Use:   "init (bash|zsh|fish)",
+	Short: "Print a snippet that wires the prompt and completion into a shell",
+	Long: `Print a shell snippet that enables kedit completion and shows the current
+context in the prompt using 'kedit prompt'.
+
+  bash:  eval "$(kedit init bash)"     in ~/.bashrc
+  zsh:   eval "$(kedit init zsh)"      in ~/.zshrc
+  fish:  kedit init fish | source      in ~/.config/fish/config.fish`,
+	Args:      cobra.ExactArgs(1), // Requires the shell name
+	ValidArgs: []string{"bash", "zsh", "fish"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		snippet, ok := shellInitSnippets[args[0]]
+		if !ok {
+			return fmt.Errorf("unsupported shell '%s'. Must be one of: bash, zsh, fish", args[0])
+		}
+		fmt.Print(snippet)
+		return nil
+	},
+}
+
+func init() {
+	promptCmd.Flags().StringVar(&promptFormat, "format", "", "Go template for the prompt (default \""+defaultPromptFormat+"\")")
+	promptCmd.Flags().StringArrayVar(&promptColors, "color", nil, "Color rule <pattern>=<color>, e.g. 'prod-*=red' (can be repeated)")
+	promptCmd.Flags().StringVar(&promptShell, "shell", "", "Escape color codes for the prompt of this shell (bash, zsh, fish)")
+	promptCmd.Flags().BoolVar(&promptNoColor, "no-color", false, "Do not color the prompt")
+	rootCmd.AddCommand(promptCmd)
+	rootCmd.AddCommand(initCmd)
+}
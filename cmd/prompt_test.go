@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromptCommand(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "kedit-test-prompt-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+	t.Setenv("KEDIT_CONFIG", filepath.Join(tempDir, "kedit.yaml"))
+	t.Setenv("KUBECONFIG", "")
+
+	kubeconfigPath := filepath.Join(tempDir, "config")
+	err = ioutil.WriteFile(kubeconfigPath, []byte(`
+apiVersion: v1
+clusters:
+- cluster:
+    server: https://prod
+  name: prod-cluster
+contexts:
+- context:
+    cluster: prod-cluster
+    user: admin
+    namespace: payments
+  name: prod-eu
+- context:
+    cluster: prod-cluster
+    user: admin
+  name: dev
+current-context: prod-eu
+kind: Config
+users:
+- name: admin
+  user:
+    token: token1
+`), 0644)
+	assert.NoError(t, err)
+
+	// Test the default format and a custom one.
+	t.Run("prompt format", func(t *testing.T) {
+		output := executeCommandC(t, "prompt", "--kubeconfig", kubeconfigPath)
+		assert.Equal(t, "prod-eu:payments", output)
+
+		output = executeCommandC(t, "prompt", "--format", "{{.Cluster}}/{{.User}}", "--kubeconfig", kubeconfigPath)
+		assert.Equal(t, "prod-cluster/admin", output)
+	})
+
+	// Test color rules from flags and from the kedit config file, with shell escaping.
+	t.Run("prompt colors", func(t *testing.T) {
+		output := executeCommandC(t, "prompt", "--color", "prod-*=bold+red", "--kubeconfig", kubeconfigPath)
+		assert.Equal(t, "\x1b[1;31mprod-eu:payments\x1b[0m", output)
+
+		output = executeCommandC(t, "prompt", "--color", "prod-*=red", "--shell", "bash", "--kubeconfig", kubeconfigPath)
+		assert.Equal(t, "\x01\x1b[31m\x02prod-eu:payments\x01\x1b[0m\x02", output)
+
+		err := ioutil.WriteFile(filepath.Join(tempDir, "kedit.yaml"), []byte(`
+prompt:
+  format: '{{.Context}}'
+  colors:
+  - pattern: prod-*
+    color: green
+`), 0644)
+		assert.NoError(t, err)
+		defer os.Remove(filepath.Join(tempDir, "kedit.yaml"))
+
+		output = executeCommandC(t, "prompt", "--shell", "zsh", "--kubeconfig", kubeconfigPath)
+		assert.Equal(t, "%{\x1b[32m%}prod-eu%{\x1b[0m%}", output)
+
+		output = executeCommandC(t, "prompt", "--no-color", "--kubeconfig", kubeconfigPath)
+		assert.Equal(t, "prod-eu", output)
+
+		output = executeCommandC(t, "prompt", "--color", "prod-*=purple", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Error: unknown color 'purple'")
+	})
+
+	// Test that $KUBECONFIG is honored and missing files are skipped.
+	t.Run("prompt KUBECONFIG", func(t *testing.T) {
+		overridePath := filepath.Join(tempDir, "override")
+		err := ioutil.WriteFile(overridePath, []byte("current-context: dev\n"), 0644)
+		assert.NoError(t, err)
+
+		t.Setenv("KUBECONFIG", filepath.Join(tempDir, "missing")+string(os.PathListSeparator)+overridePath+string(os.PathListSeparator)+kubeconfigPath)
+		output := executeCommandC(t, "prompt")
+		assert.Equal(t, "dev:default", output)
+
+		// Nothing is printed without a current-context.
+		emptyPath := filepath.Join(tempDir, "empty")
+		assert.NoError(t, ioutil.WriteFile(emptyPath, []byte("apiVersion: v1\nkind: Config\n"), 0644))
+		output = executeCommandC(t, "prompt", "--kubeconfig", emptyPath)
+		assert.Empty(t, output)
+	})
+}
+
+func TestInitCommand(t *testing.T) {
+	output := executeCommandC(t, "init", "zsh")
+	assert.Contains(t, output, "source <(kedit completion zsh)")
+	assert.Contains(t, output, "kedit prompt --shell zsh")
+
+	output = executeCommandC(t, "init", "fish")
+	assert.Contains(t, output, "kedit completion fish | source")
+
+	// Evaluating the bash snippet twice adds the prompt segment only once.
+	if bash, err := exec.LookPath("bash"); err == nil {
+		snippet := strings.Replace(executeCommandC(t, "init", "bash"), "source <(kedit completion bash)", "", 1)
+		script := "PS1='$ '\nPROMPT_COMMAND=''\n" + snippet + "\n" + snippet + "\nprintf '%s|%s' \"$PS1\" \"$PROMPT_COMMAND\"\n"
+		out, err := exec.Command(bash, "--norc", "--noprofile", "-c", script).Output()
+		assert.NoError(t, err)
+		assert.Equal(t, "${KEDIT_PROMPT:+[$KEDIT_PROMPT] }$ |__kedit_prompt", string(out))
+	}
+
+	output = executeCommandC(t, "init", "tcsh")
+	assert.Contains(t, output, "Error: unsupported shell 'tcsh'. Must be one of: bash, zsh, fish")
+}
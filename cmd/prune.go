@@ -1,97 +1,467 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"k8s.io/client-go/tools/clientcmd/api"
 )
 
+var (
+	pruneExpired     bool     // Flag to prune users whose client certificates have expired
+	pruneContexts    bool     // Flag to prune contexts whose cluster or user does not exist
+	pruneAll         bool     // Flag to prune dangling contexts as well as unreferenced clusters and users
+	pruneDryRun      bool     // Flag to only list what would be pruned
+	pruneKeep        []string // Flag for glob patterns of names that are never pruned
+	pruneInteractive bool     // Flag to pick the items to prune from the candidate list
+	pruneYes         bool     // Flag to prune a kubeconfig without contexts without asking
+)
+
+// pruneCandidate is an item that a prune mode has selected for removal.
+type pruneCandidate struct {
+	kind   string // "context", "cluster" or "user"
+	name   string
+	reason string
+}
+
 // pruneCmd represents the prune command
 var pruneCmd = &cobra.Command{
 	Use:   "prune",
 	Short: "Remove unreferenced clusters and users",
-	Long:  `Remove all clusters and users from the kubeconfig file that are not referenced by any existing context.`,
-	Args:  cobra.NoArgs,
+	Long: `Remove all clusters and users from the kubeconfig file that are not referenced by any existing context.
+
+--contexts instead removes every context that references a cluster or user
+which does not exist, clearing the current-context if it was one of them.
+--all removes such dangling contexts first and then all unreferenced clusters and users.
+--expired instead removes every user whose client certificate has expired.
+
+--dry-run lists exactly what would be pruned without changing the file.
+--keep excludes names matching a glob pattern (e.g. 'prod-*') from pruning;
+it can be repeated, and patterns listed under prune.keep in the kedit config
+file ($KEDIT_CONFIG, default <user config dir>/kedit/config.yaml) always apply.
+--interactive lets you pick the items to prune from the candidate list.
+
+If the kubeconfig has no contexts, or --all removes all of them as dangling,
+every cluster and user is unreferenced; kedit then lists them and asks before
+pruning, unless --yes or --interactive is given.`,
+	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if pruneExpired && (pruneContexts || pruneAll) {
+			return fmt.Errorf("flag --expired cannot be combined with --contexts or --all")
+		}
+
+		settings, err := loadKeditSettings()
+		if err != nil {
+			return err
+		}
+		selector := &pruneSelector{
+			keepPatterns: append(append([]string{}, pruneKeep...), settings.Prune.Keep...),
+			interactive:  pruneInteractive,
+			reader:       bufio.NewReader(os.Stdin),
+		}
+
 		config, err := loadKubeconfig(resolvedKubeconfigPath)
 		if err != nil {
 			return fmt.Errorf("error loading kubeconfig from '%s': %w", resolvedKubeconfigPath, err)
 		}
 
-		if len(config.Contexts) == 0 {
-			// If there are no contexts, all clusters and users are unreferenced.
-			originalClusterCount := len(config.Clusters)
-			originalUserCount := len(config.AuthInfos)
+		if pruneExpired {
+			return pruneExpiredUsers(config, selector)
+		}
 
-			if originalClusterCount == 0 && originalUserCount == 0 {
-				fmt.Println("No contexts, clusters, or users found. Nothing to prune.")
+		if pruneContexts && !pruneAll {
+			candidates, err := selector.choose(danglingContextCandidates(config))
+			if err != nil {
+				return err
+			}
+			if pruneDryRun {
+				reportDryRun(candidates)
 				return nil
 			}
-
-			config.Clusters = make(map[string]*api.Cluster)
-			config.AuthInfos = make(map[string]*api.AuthInfo)
-
+			if len(candidates) == 0 {
+				fmt.Printf("No dangling contexts found in '%s'. Nothing to prune.\n", resolvedKubeconfigPath)
+				return nil
+			}
+			currentContext := config.CurrentContext
+			applyPruneCandidates(config, candidates)
 			if err := saveKubeconfig(config, resolvedKubeconfigPath); err != nil {
-				return fmt.Errorf("error saving kubeconfig to '%s' after pruning all clusters/users: %w", resolvedKubeconfigPath, err)
+				return fmt.Errorf("error saving kubeconfig to '%s' after pruning contexts: %w", resolvedKubeconfigPath, err)
 			}
-			fmt.Printf("No contexts found. Pruned %d cluster(s) and %d user(s) from '%s'.\n", originalClusterCount, originalUserCount, resolvedKubeconfigPath)
+			reportPruneCandidates(candidates, currentContext)
+			fmt.Printf("Pruned %d dangling context(s) from '%s'.\n", len(candidates), resolvedKubeconfigPath)
 			return nil
 		}
 
-		// Collect all referenced cluster and user names
-		referencedClusters := make(map[string]bool)
-		referencedUsers := make(map[string]bool)
-		for _, context := range config.Contexts {
-			if context.Cluster != "" {
-				referencedClusters[context.Cluster] = true
+		if pruneAll {
+			// Dangling contexts go first, so that the clusters and users only they
+			// referenced are pruned as unreferenced in the same run.
+			contextCandidates, err := selector.choose(danglingContextCandidates(config))
+			if err != nil {
+				return err
 			}
-			if context.AuthInfo != "" { // AuthInfo is the user name in a context
-				referencedUsers[context.AuthInfo] = true
+			currentContext := config.CurrentContext
+			applyPruneCandidates(config, contextCandidates)
+			entryCandidates, err := selector.choose(unreferencedEntryCandidates(config))
+			if err != nil {
+				return err
 			}
-		}
-
-		// Prune unreferenced clusters
-		originalClusterCount := len(config.Clusters)
-		prunedClustersCount := 0
-		newClusters := make(map[string]*api.Cluster)
-		for name, cluster := range config.Clusters {
-			if referencedClusters[name] {
-				newClusters[name] = cluster
-			} else {
-				prunedClustersCount++
+			if pruneDryRun {
+				reportDryRun(append(contextCandidates, entryCandidates...))
+				return nil
+			}
+			if len(contextCandidates) == 0 && len(entryCandidates) == 0 {
+				fmt.Printf("No dangling contexts or unreferenced clusters or users found in '%s'. Nothing to prune.\n", resolvedKubeconfigPath)
+				return nil
 			}
+			if len(config.Contexts) == 0 && len(entryCandidates) > 0 {
+				ok, err := confirmPruneWithoutContexts(entryCandidates)
+				if err != nil || !ok {
+					return err
+				}
+			}
+			applyPruneCandidates(config, entryCandidates)
+			if err := saveKubeconfig(config, resolvedKubeconfigPath); err != nil {
+				return fmt.Errorf("error saving kubeconfig to '%s' after pruning: %w", resolvedKubeconfigPath, err)
+			}
+			reportPruneCandidates(contextCandidates, currentContext)
+			prunedClustersCount, prunedUsersCount := countPruneCandidates(entryCandidates)
+			fmt.Printf("Pruned %d context(s), %d cluster(s) and %d user(s) from '%s'.\n",
+				len(contextCandidates), prunedClustersCount, prunedUsersCount, resolvedKubeconfigPath)
+			return nil
 		}
-		config.Clusters = newClusters
 
-		// Prune unreferenced users
+		originalClusterCount := len(config.Clusters)
 		originalUserCount := len(config.AuthInfos)
-		prunedUsersCount := 0
-		newAuthInfos := make(map[string]*api.AuthInfo)
-		for name, user := range config.AuthInfos {
-			if referencedUsers[name] {
-				newAuthInfos[name] = user
-			} else {
-				prunedUsersCount++
-			}
+
+		if len(config.Contexts) == 0 && originalClusterCount == 0 && originalUserCount == 0 {
+			fmt.Println("No contexts, clusters, or users found. Nothing to prune.")
+			return nil
 		}
-		config.AuthInfos = newAuthInfos
 
-		if prunedClustersCount == 0 && prunedUsersCount == 0 {
+		candidates, err := selector.choose(unreferencedEntryCandidates(config))
+		if err != nil {
+			return err
+		}
+		if pruneDryRun {
+			reportDryRun(candidates)
+			return nil
+		}
+		if len(candidates) == 0 {
 			fmt.Printf("No unreferenced clusters or users found in '%s'. Nothing to prune.\n", resolvedKubeconfigPath)
 			return nil
 		}
+		if len(config.Contexts) == 0 {
+			ok, err := confirmPruneWithoutContexts(candidates)
+			if err != nil || !ok {
+				return err
+			}
+		}
 
+		applyPruneCandidates(config, candidates)
 		if err := saveKubeconfig(config, resolvedKubeconfigPath); err != nil {
 			return fmt.Errorf("error saving kubeconfig to '%s' after pruning: %w", resolvedKubeconfigPath, err)
 		}
 
+		prunedClustersCount, prunedUsersCount := countPruneCandidates(candidates)
+		if len(config.Contexts) == 0 {
+			fmt.Printf("No contexts found. Pruned %d cluster(s) and %d user(s) from '%s'.\n", prunedClustersCount, prunedUsersCount, resolvedKubeconfigPath)
+			return nil
+		}
 		fmt.Printf("Pruned %d cluster(s) (out of %d) and %d user(s) (out of %d) from '%s'.\n",
 			prunedClustersCount, originalClusterCount, prunedUsersCount, originalUserCount, resolvedKubeconfigPath)
 		return nil
 	},
 }
 
+// unreferencedEntryCandidates returns all clusters and users that are not referenced by any context.
+func unreferencedEntryCandidates(config *api.Config) []pruneCandidate {
+	// Collect all referenced cluster and user names
+	referencedClusters := make(map[string]bool)
+	referencedUsers := make(map[string]bool)
+	for _, context := range config.Contexts {
+		if context.Cluster != "" {
+			referencedClusters[context.Cluster] = true
+		}
+		if context.AuthInfo != "" { // AuthInfo is the user name in a context
+			referencedUsers[context.AuthInfo] = true
+		}
+	}
+
+	var candidates []pruneCandidate
+	for _, name := range sortedKeys(config.Clusters) {
+		if !referencedClusters[name] {
+			candidates = append(candidates, pruneCandidate{kind: "cluster", name: name, reason: "not referenced by any context"})
+		}
+	}
+	for _, name := range sortedKeys(config.AuthInfos) {
+		if !referencedUsers[name] {
+			candidates = append(candidates, pruneCandidate{kind: "user", name: name, reason: "not referenced by any context"})
+		}
+	}
+	return candidates
+}
+
+// danglingContextCandidates returns all contexts that reference a cluster or user which does not exist.
+func danglingContextCandidates(config *api.Config) []pruneCandidate {
+	var candidates []pruneCandidate
+	for _, name := range sortedKeys(config.Contexts) {
+		missing := missingContextReferences(config, config.Contexts[name])
+		if len(missing) > 0 {
+			candidates = append(candidates, pruneCandidate{kind: "context", name: name, reason: strings.Join(missing, " and ") + " not found"})
+		}
+	}
+	return candidates
+}
+
+// missingContextReferences describes the cluster and user references of a context
+// that do not exist in the config.
+func missingContextReferences(config *api.Config, context *api.Context) []string {
+	var missing []string
+	if context.Cluster != "" {
+		if _, ok := config.Clusters[context.Cluster]; !ok {
+			missing = append(missing, fmt.Sprintf("cluster '%s'", context.Cluster))
+		}
+	}
+	if context.AuthInfo != "" {
+		if _, ok := config.AuthInfos[context.AuthInfo]; !ok {
+			missing = append(missing, fmt.Sprintf("user '%s'", context.AuthInfo))
+		}
+	}
+	return missing
+}
+
+// expiredUserCandidates returns all users whose client certificate has expired.
+func expiredUserCandidates(config *api.Config) []pruneCandidate {
+	now := time.Now()
+	var candidates []pruneCandidate
+	for _, name := range sortedKeys(config.AuthInfos) {
+		certs, err := clientCertificates(config.AuthInfos[name])
+		if err != nil || len(certs) == 0 {
+			continue
+		}
+		if certs[0].NotAfter.Before(now) {
+			reason := "client certificate expired on " + certs[0].NotAfter.UTC().Format(time.RFC3339)
+			candidates = append(candidates, pruneCandidate{kind: "user", name: name, reason: reason})
+		}
+	}
+	return candidates
+}
+
+// pruneExpiredUsers removes every user whose client certificate has expired and saves the config.
+func pruneExpiredUsers(config *api.Config, selector *pruneSelector) error {
+	candidates, err := selector.choose(expiredUserCandidates(config))
+	if err != nil {
+		return err
+	}
+	if pruneDryRun {
+		reportDryRun(candidates)
+		return nil
+	}
+	if len(candidates) == 0 {
+		fmt.Printf("No users with expired client certificates found in '%s'. Nothing to prune.\n", resolvedKubeconfigPath)
+		return nil
+	}
+
+	applyPruneCandidates(config, candidates)
+	if err := saveKubeconfig(config, resolvedKubeconfigPath); err != nil {
+		return fmt.Errorf("error saving kubeconfig to '%s' after pruning expired users: %w", resolvedKubeconfigPath, err)
+	}
+
+	reportPruneCandidates(candidates, config.CurrentContext)
+	for _, name := range sortedKeys(config.Contexts) {
+		userName := config.Contexts[name].AuthInfo
+		if _, ok := config.AuthInfos[userName]; userName != "" && !ok {
+			fmt.Printf("Warning: context '%s' references the pruned user '%s'.\n", name, userName)
+		}
+	}
+	fmt.Printf("Pruned %d user(s) with expired client certificates from '%s'.\n", len(candidates), resolvedKubeconfigPath)
+	return nil
+}
+
+// applyPruneCandidates removes the candidates from the config, clearing the
+// current-context if it is removed.
+func applyPruneCandidates(config *api.Config, candidates []pruneCandidate) {
+	for _, candidate := range candidates {
+		switch candidate.kind {
+		case "context":
+			delete(config.Contexts, candidate.name)
+			if config.CurrentContext == candidate.name {
+				config.CurrentContext = ""
+			}
+		case "cluster":
+			delete(config.Clusters, candidate.name)
+		case "user":
+			delete(config.AuthInfos, candidate.name)
+		}
+	}
+}
+
+// reportPruneCandidates reports each removed candidate once the config has been
+// saved. currentContext is the current-context from before the removal.
+func reportPruneCandidates(candidates []pruneCandidate, currentContext string) {
+	for _, candidate := range candidates {
+		fmt.Printf("Pruned %s '%s' (%s).\n", candidate.kind, candidate.name, candidate.reason)
+		if candidate.kind == "context" && candidate.name == currentContext {
+			fmt.Printf("Cleared current-context, which pointed to the pruned context '%s'.\n", candidate.name)
+		}
+	}
+}
+
+// confirmPruneWithoutContexts asks before pruning the candidates of a kubeconfig
+// without contexts, unless --yes or --interactive is given. Without contexts every
+// cluster and user is unreferenced, which more often means a broken or
+// half-written kubeconfig than entries nobody needs.
+func confirmPruneWithoutContexts(candidates []pruneCandidate) (bool, error) {
+	if pruneInteractive || pruneYes {
+		return true, nil
+	}
+	fmt.Printf("'%s' has no contexts, so every cluster and user is unreferenced:\n", resolvedKubeconfigPath)
+	for _, candidate := range candidates {
+		fmt.Printf("  %s '%s'\n", candidate.kind, candidate.name)
+	}
+	ok, err := confirm(fmt.Sprintf("Prune all %d of them?", len(candidates)), "pass --yes or --interactive to prune a kubeconfig without contexts")
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		fmt.Println("No changes made.")
+	}
+	return ok, nil
+}
+
+// countPruneCandidates returns the number of clusters and users among the candidates.
+func countPruneCandidates(candidates []pruneCandidate) (int, int) {
+	clusters, users := 0, 0
+	for _, candidate := range candidates {
+		switch candidate.kind {
+		case "cluster":
+			clusters++
+		case "user":
+			users++
+		}
+	}
+	return clusters, users
+}
+
+// reportDryRun lists the candidates that would be pruned.
+func reportDryRun(candidates []pruneCandidate) {
+	for _, candidate := range candidates {
+		fmt.Printf("Would prune %s '%s' (%s).\n", candidate.kind, candidate.name, candidate.reason)
+	}
+	fmt.Printf("Dry run: %d item(s) would be pruned from '%s'. No changes made.\n", len(candidates), resolvedKubeconfigPath)
+}
+
+// pruneSelector narrows down prune candidates using keep patterns and, optionally,
+// an interactive selection.
+type pruneSelector struct {
+	keepPatterns []string
+	interactive  bool
+	reader       *bufio.Reader
+}
+
+// choose returns the candidates that are neither kept by a pattern nor deselected interactively.
+func (s *pruneSelector) choose(candidates []pruneCandidate) ([]pruneCandidate, error) {
+	var remaining []pruneCandidate
+	for _, candidate := range candidates {
+		if pattern, kept := s.keepPattern(candidate.name); kept {
+			fmt.Printf("Keeping %s '%s' (matches keep pattern '%s').\n", candidate.kind, candidate.name, pattern)
+			continue
+		}
+		remaining = append(remaining, candidate)
+	}
+	if !s.interactive || len(remaining) == 0 {
+		return remaining, nil
+	}
+
+	fmt.Println("Prune candidates:")
+	for i, candidate := range remaining {
+		fmt.Printf("  %d) %s '%s' (%s)\n", i+1, candidate.kind, candidate.name, candidate.reason)
+	}
+	fmt.Print("Select items to prune (e.g. 1,3-4, 'all' or 'none') [none]: ")
+	line, err := s.reader.ReadString('\n')
+	if err != nil && line == "" {
+		// No input (e.g. EOF): prune nothing.
+		fmt.Println()
+		return nil, nil
+	}
+	indexes, err := parseSelection(strings.TrimSpace(line), len(remaining))
+	if err != nil {
+		return nil, err
+	}
+	chosen := make([]pruneCandidate, 0, len(indexes))
+	for _, index := range indexes {
+		chosen = append(chosen, remaining[index])
+	}
+	return chosen, nil
+}
+
+// keepPattern returns the first keep pattern that matches name.
+func (s *pruneSelector) keepPattern(name string) (string, bool) {
+	for _, pattern := range s.keepPatterns {
+		if matchGlob(pattern, name) {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+// parseSelection parses a selection such as "1,3-4", "all" or "none" over n
+// items and returns the selected zero-based indexes in ascending order.
+func parseSelection(selection string, n int) ([]int, error) {
+	switch strings.ToLower(selection) {
+	case "", "none", "n":
+		return nil, nil
+	case "all", "a":
+		indexes := make([]int, n)
+		for i := range indexes {
+			indexes[i] = i
+		}
+		return indexes, nil
+	}
+
+	selected := make([]bool, n)
+	for _, part := range strings.Split(selection, ",") {
+		part = strings.TrimSpace(part)
+		first, last, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(strings.TrimSpace(first))
+		if err != nil {
+			return nil, fmt.Errorf("invalid selection '%s'", part)
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(strings.TrimSpace(last)); err != nil {
+				return nil, fmt.Errorf("invalid selection '%s'", part)
+			}
+		}
+		if start < 1 || end > n || start > end {
+			return nil, fmt.Errorf("selection '%s' is out of range 1-%d", part, n)
+		}
+		for i := start; i <= end; i++ {
+			selected[i-1] = true
+		}
+	}
+
+	var indexes []int
+	for i, ok := range selected {
+		if ok {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes, nil
+}
+
 func init() {
+	pruneCmd.Flags().BoolVar(&pruneContexts, "contexts", false, "Prune contexts whose cluster or user does not exist")
+	pruneCmd.Flags().BoolVar(&pruneAll, "all", false, "Prune dangling contexts and unreferenced clusters and users")
+	pruneCmd.Flags().BoolVar(&pruneExpired, "expired", false, "Prune users whose client certificates have expired")
+	pruneCmd.Flags().BoolVar(&pruneDryRun, "dry-run", false, "List what would be pruned without changing the file")
+	pruneCmd.Flags().StringArrayVar(&pruneKeep, "keep", nil, "Glob pattern of names that are never pruned (can be repeated)")
+	pruneCmd.Flags().BoolVarP(&pruneInteractive, "interactive", "i", false, "Pick the items to prune from the candidate list")
+	pruneCmd.Flags().BoolVarP(&pruneYes, "yes", "y", false, "Prune a kubeconfig without contexts without asking")
 	rootCmd.AddCommand(pruneCmd)
 }
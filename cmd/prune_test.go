@@ -6,12 +6,20 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
 )
 
 func TestPruneCommand(t *testing.T) {
+	// Keep patterns of the developer's own kedit config must not apply.
+	settingsDir, err := ioutil.TempDir("", "kedit-test-prune-settings-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(settingsDir)
+	t.Setenv("KEDIT_CONFIG", filepath.Join(settingsDir, "config.yaml"))
+
 	// Helper to create a kubeconfig with various states.
 	createKubeconfigForPrune := func(tempDir string, content string) string {
 		kubeconfigPath := filepath.Join(tempDir, "config")
@@ -155,15 +163,303 @@ users:
     token: token2
 `)
 
+		// Without an answer nothing is pruned.
 		output := executeCommandC(t, "prune", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "'"+kubeconfigPath+"' has no contexts, so every cluster and user is unreferenced:\n  cluster 'cluster1'\n")
+		assert.Contains(t, output, "Error: no answer given on stdin; pass --yes or --interactive")
+		config, err := clientcmd.LoadFromFile(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.Len(t, config.Clusters, 2)
+
+		output = executeCommandWithInput(t, "n\n", "prune", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Prune all 4 of them? [y/N]: No changes made.")
+
+		output = executeCommandWithInput(t, "y\n", "prune", "--kubeconfig", kubeconfigPath)
 		expectedOutput := "No contexts found. Pruned 2 cluster(s) and 2 user(s) from '" + kubeconfigPath + "'."
-		assert.Equal(t, expectedOutput, output)
+		assert.Contains(t, output, expectedOutput)
 
 		// Verify the kubeconfig content.
-		config, err := clientcmd.LoadFromFile(kubeconfigPath)
+		config, err = clientcmd.LoadFromFile(kubeconfigPath)
 		assert.NoError(t, err)
 		assert.Len(t, config.Clusters, 0)
 		assert.Len(t, config.AuthInfos, 0)
 		assert.Len(t, config.Contexts, 0)
 	})
+
+	// Test pruning users with expired client certificates.
+	t.Run("prune expired", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-prune-expired-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+
+		ca := newTestCA(t, "test-ca")
+		expired := newTestClientCert(t, ca, "expired", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+		valid := newTestClientCert(t, ca, "valid", time.Now().Add(365*24*time.Hour))
+
+		config := api.NewConfig()
+		config.Clusters["cluster1"] = &api.Cluster{Server: "https://cluster1"}
+		config.AuthInfos["expired-user"] = &api.AuthInfo{ClientCertificateData: expired.certPEM, ClientKeyData: expired.keyPEM}
+		config.AuthInfos["valid-user"] = &api.AuthInfo{ClientCertificateData: valid.certPEM, ClientKeyData: valid.keyPEM}
+		config.AuthInfos["token-user"] = &api.AuthInfo{Token: "token"}
+		config.Contexts["expired-context"] = &api.Context{Cluster: "cluster1", AuthInfo: "expired-user"}
+		kubeconfigPath := filepath.Join(tempDir, "config")
+		assert.NoError(t, saveKubeconfig(config, kubeconfigPath))
+
+		output := executeCommandC(t, "prune", "--expired", "--kubeconfig", kubeconfigPath)
+		expectedOutput := "Pruned user 'expired-user' (client certificate expired on 2020-01-01T00:00:00Z).\n" +
+			"Warning: context 'expired-context' references the pruned user 'expired-user'.\n" +
+			"Pruned 1 user(s) with expired client certificates from '" + kubeconfigPath + "'."
+		assert.Equal(t, expectedOutput, output)
+
+		loaded, err := clientcmd.LoadFromFile(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.Nil(t, loaded.AuthInfos["expired-user"])
+		assert.NotNil(t, loaded.AuthInfos["valid-user"])
+		assert.NotNil(t, loaded.AuthInfos["token-user"])
+		assert.Len(t, loaded.Clusters, 1)
+	})
+
+	// Helper content with one healthy and two dangling contexts.
+	danglingContent := `
+apiVersion: v1
+clusters:
+- cluster:
+    server: https://cluster1
+  name: cluster1
+- cluster:
+    server: https://cluster2
+  name: cluster2
+contexts:
+- context:
+    cluster: cluster1
+    user: user1
+  name: healthy-context
+- context:
+    cluster: missing-cluster
+    user: user2
+  name: no-cluster-context
+- context:
+    cluster: cluster2
+    user: missing-user
+  name: no-user-context
+current-context: no-user-context
+kind: Config
+preferences: {}
+users:
+- name: user1
+  user:
+    token: token1
+- name: user2
+  user:
+    token: token2
+`
+
+	// Test pruning dangling contexts.
+	t.Run("prune contexts", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-prune-contexts-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		kubeconfigPath := createKubeconfigForPrune(tempDir, danglingContent)
+
+		output := executeCommandC(t, "prune", "--contexts", "--kubeconfig", kubeconfigPath)
+		expectedOutput := "Pruned context 'no-cluster-context' (cluster 'missing-cluster' not found).\n" +
+			"Pruned context 'no-user-context' (user 'missing-user' not found).\n" +
+			"Cleared current-context, which pointed to the pruned context 'no-user-context'.\n" +
+			"Pruned 2 dangling context(s) from '" + kubeconfigPath + "'."
+		assert.Equal(t, expectedOutput, output)
+
+		config, err := clientcmd.LoadFromFile(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.Len(t, config.Contexts, 1)
+		assert.NotNil(t, config.Contexts["healthy-context"])
+		assert.Empty(t, config.CurrentContext)
+		// Clusters and users are left alone without --all.
+		assert.Len(t, config.Clusters, 2)
+		assert.Len(t, config.AuthInfos, 2)
+	})
+
+	// Test pruning dangling contexts and unreferenced entries together.
+	t.Run("prune all", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-prune-all-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		kubeconfigPath := createKubeconfigForPrune(tempDir, danglingContent)
+
+		output := executeCommandC(t, "prune", "--all", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Pruned 2 context(s), 1 cluster(s) and 1 user(s) from '"+kubeconfigPath+"'.")
+
+		config, err := clientcmd.LoadFromFile(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.Len(t, config.Contexts, 1)
+		assert.Len(t, config.Clusters, 1)
+		assert.NotNil(t, config.Clusters["cluster1"])
+		assert.Len(t, config.AuthInfos, 1)
+		assert.NotNil(t, config.AuthInfos["user1"])
+	})
+
+	// Test that --all asks before emptying a kubeconfig without usable contexts.
+	t.Run("prune all no contexts", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-prune-all-no-contexts-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		kubeconfigPath := createKubeconfigForPrune(tempDir, `
+apiVersion: v1
+clusters:
+- cluster:
+    server: https://cluster1
+  name: cluster1
+contexts:
+- context:
+    cluster: missing-cluster
+    user: user1
+  name: dangling-context
+current-context: dangling-context
+kind: Config
+preferences: {}
+users:
+- name: user1
+  user:
+    token: token1
+`)
+
+		output := executeCommandC(t, "prune", "--all", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Error: no answer given on stdin; pass --yes or --interactive")
+		// Nothing is reported as pruned before the file is saved.
+		assert.NotContains(t, output, "Pruned context")
+		config, err := clientcmd.LoadFromFile(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.Len(t, config.Contexts, 1)
+		assert.Len(t, config.Clusters, 1)
+		assert.Len(t, config.AuthInfos, 1)
+
+		output = executeCommandWithInput(t, "n\n", "prune", "--all", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Prune all 2 of them? [y/N]: No changes made.")
+
+		output = executeCommandC(t, "prune", "--all", "--yes", "--kubeconfig", kubeconfigPath)
+		expectedOutput := "Pruned context 'dangling-context' (cluster 'missing-cluster' not found).\n" +
+			"Cleared current-context, which pointed to the pruned context 'dangling-context'.\n" +
+			"Pruned 1 context(s), 1 cluster(s) and 1 user(s) from '" + kubeconfigPath + "'."
+		assert.Equal(t, expectedOutput, output)
+		config, err = clientcmd.LoadFromFile(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.Empty(t, config.Contexts)
+		assert.Empty(t, config.Clusters)
+		assert.Empty(t, config.AuthInfos)
+		assert.Empty(t, config.CurrentContext)
+	})
+
+	// Test pruning contexts when none are dangling.
+	t.Run("prune contexts none dangling", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-prune-contexts-none-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		kubeconfigPath := filepath.Join(tempDir, "config")
+
+		output := executeCommandC(t, "prune", "--contexts", "--kubeconfig", kubeconfigPath)
+		expectedOutput := "No dangling contexts found in '" + kubeconfigPath + "'. Nothing to prune."
+		assert.Equal(t, expectedOutput, output)
+	})
+
+	// Test that --dry-run lists candidates without changing the file.
+	t.Run("prune dry run", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-prune-dry-run-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		kubeconfigPath := createKubeconfigForPrune(tempDir, danglingContent)
+
+		output := executeCommandC(t, "prune", "--all", "--dry-run", "--kubeconfig", kubeconfigPath)
+		expectedOutput := "Would prune context 'no-cluster-context' (cluster 'missing-cluster' not found).\n" +
+			"Would prune context 'no-user-context' (user 'missing-user' not found).\n" +
+			"Would prune cluster 'cluster2' (not referenced by any context).\n" +
+			"Would prune user 'user2' (not referenced by any context).\n" +
+			"Dry run: 4 item(s) would be pruned from '" + kubeconfigPath + "'. No changes made."
+		assert.Equal(t, expectedOutput, output)
+
+		config, err := clientcmd.LoadFromFile(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.Len(t, config.Contexts, 3)
+		assert.Len(t, config.Clusters, 2)
+		assert.Len(t, config.AuthInfos, 2)
+		assert.Equal(t, "no-user-context", config.CurrentContext)
+	})
+
+	// Test keep patterns from the flag and from the kedit config file.
+	t.Run("prune keep", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-prune-keep-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		kubeconfigPath := createKubeconfigForPrune(tempDir, `
+apiVersion: v1
+clusters:
+- cluster:
+    server: https://prod-cluster
+  name: prod-cluster
+- cluster:
+    server: https://dev-cluster
+  name: dev-cluster
+contexts: []
+kind: Config
+preferences: {}
+users:
+- name: admin-user
+  user:
+    token: token1
+- name: dev-user
+  user:
+    token: token2
+`)
+		settingsPath := filepath.Join(tempDir, "kedit.yaml")
+		err = ioutil.WriteFile(settingsPath, []byte("prune:\n  keep:\n  - admin-*\n"), 0644)
+		assert.NoError(t, err)
+		t.Setenv("KEDIT_CONFIG", settingsPath)
+
+		output := executeCommandC(t, "prune", "--keep", "prod-*", "--yes", "--kubeconfig", kubeconfigPath)
+		expectedOutput := "Keeping cluster 'prod-cluster' (matches keep pattern 'prod-*').\n" +
+			"Keeping user 'admin-user' (matches keep pattern 'admin-*').\n" +
+			"No contexts found. Pruned 1 cluster(s) and 1 user(s) from '" + kubeconfigPath + "'."
+		assert.Equal(t, expectedOutput, output)
+
+		config, err := clientcmd.LoadFromFile(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.Len(t, config.Clusters, 1)
+		assert.NotNil(t, config.Clusters["prod-cluster"])
+		assert.Len(t, config.AuthInfos, 1)
+		assert.NotNil(t, config.AuthInfos["admin-user"])
+	})
+
+	// Test picking candidates interactively.
+	t.Run("prune interactive", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-prune-interactive-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		kubeconfigPath := createKubeconfigForPrune(tempDir, danglingContent)
+
+		output := executeCommandWithInput(t, "2\n", "prune", "--contexts", "--interactive", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Prune candidates:\n  1) context 'no-cluster-context' (cluster 'missing-cluster' not found)\n  2) context 'no-user-context'")
+		assert.Contains(t, output, "Pruned 1 dangling context(s) from '"+kubeconfigPath+"'.")
+
+		config, err := clientcmd.LoadFromFile(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.NotNil(t, config.Contexts["no-cluster-context"])
+		assert.Nil(t, config.Contexts["no-user-context"])
+	})
+}
+
+func TestParseSelection(t *testing.T) {
+	indexes, err := parseSelection("1, 3-4", 5)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 2, 3}, indexes)
+
+	indexes, err = parseSelection("all", 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2}, indexes)
+
+	indexes, err = parseSelection("none", 3)
+	assert.NoError(t, err)
+	assert.Empty(t, indexes)
+
+	_, err = parseSelection("4", 3)
+	assert.Error(t, err)
+	_, err = parseSelection("x", 3)
+	assert.Error(t, err)
 }
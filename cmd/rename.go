@@ -1,10 +1,11 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/spf13/cobra"
-	// api "k8s.io/client-go/tools/clientcmd/api" // Not directly used for type definitions here
+	"k8s.io/client-go/tools/clientcmd/api"
 )
 
 // renameCmd represents the rename command
@@ -21,17 +22,14 @@ field in the kubeconfig will also be updated to the new name.
 Arguments:
   (cluster|user|context): The type of item to rename. Must be one of 'cluster', 'user', or 'context'.
   <old_name>:             The current name of the item to be renamed.
-  <new_name>:             The desired new name for the item. The new name must not already exist for that item type.`,
-	Args: cobra.ExactArgs(3), // Requires type, old_name, and new_name
+  <new_name>:             The desired new name for the item. The new name must not already exist for that item type.
+
+On a terminal, both names can be omitted: the old name is picked with a fuzzy
+search and the new name is prompted for.`,
+	Args:              exactArgsOrPick(3, 1), // Requires type, old_name, and new_name; the names can be picked on a terminal
+	ValidArgsFunction: completeTypeThenName(itemTypes),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		itemType := args[0]
-		oldName := args[1]
-		newName := args[2]
-
-		if oldName == newName {
-			fmt.Printf("The old name and new name are identical ('%s'). No changes made.\n", oldName)
-			return nil
-		}
 
 		config, err := loadKubeconfig(resolvedKubeconfigPath)
 		if err != nil {
@@ -41,102 +39,140 @@ Arguments:
 			return fmt.Errorf("error loading kubeconfig from '%s': %w", resolvedKubeconfigPath, err)
 		}
 
-		switch itemType {
-		case "cluster":
-			// 1. Check if the old cluster name exists.
-			clusterToRename, ok := config.Clusters[oldName]
-			if !ok {
-				return fmt.Errorf("cluster '%s' not found in '%s'", oldName, resolvedKubeconfigPath)
+		var oldName, newName string
+		if len(args) == 3 {
+			oldName, newName = args[1], args[2]
+		} else {
+			oldName, err = pickName(config, itemType, resolvedKubeconfigPath)
+			if errors.Is(err, errPickerCancelled) {
+				fmt.Printf("No %s selected. No changes made.\n", itemType)
+				return nil
 			}
-
-			// 2. Check if the new cluster name already exists.
-			if _, exists := config.Clusters[newName]; exists {
-				return fmt.Errorf("a cluster with the name '%s' already exists", newName)
+			if err != nil {
+				return err
 			}
-
-			// 3. Perform rename for the cluster entry.
-			config.Clusters[newName] = clusterToRename
-			delete(config.Clusters, oldName)
-			fmt.Printf("Renamed cluster '%s' to '%s'.\n", oldName, newName)
-
-			// 4. Update references in all contexts.
-			updatedContextsCount := 0
-			for _, contextDetails := range config.Contexts {
-				if contextDetails.Cluster == oldName {
-					// contextDetails is a pointer, so this modifies the original.
-					contextDetails.Cluster = newName
-					updatedContextsCount++
-				}
-			}
-			if updatedContextsCount > 0 {
-				fmt.Printf("Updated %d context(s) to reference the new cluster name '%s'.\n", updatedContextsCount, newName)
+			newName, err = promptLine(fmt.Sprintf("New name for %s '%s': ", itemType, oldName))
+			if err != nil || newName == "" {
+				fmt.Printf("No new name given for %s '%s'. No changes made.\n", itemType, oldName)
+				return nil
 			}
+		}
 
-		case "user":
-			// 1. Check if the old user name exists.
-			userToRename, ok := config.AuthInfos[oldName]
-			if !ok {
-				return fmt.Errorf("user '%s' not found in '%s'", oldName, resolvedKubeconfigPath)
-			}
+		if oldName == newName {
+			fmt.Printf("The old name and new name are identical ('%s'). No changes made.\n", oldName)
+			return nil
+		}
 
-			// 2. Check if the new user name already exists.
-			if _, exists := config.AuthInfos[newName]; exists {
-				return fmt.Errorf("a user with the name '%s' already exists", newName)
-			}
+		messages, err := renameItem(config, itemType, oldName, newName)
+		if err != nil {
+			return err
+		}
+		for _, message := range messages {
+			fmt.Println(message)
+		}
 
-			// 3. Perform rename for the user entry.
-			config.AuthInfos[newName] = userToRename
-			delete(config.AuthInfos, oldName)
-			fmt.Printf("Renamed user '%s' to '%s'.\n", oldName, newName)
-
-			// 4. Update references in all contexts.
-			updatedContextsCount := 0
-			for _, contextDetails := range config.Contexts { // contextNameInMap not needed here for msg
-				if contextDetails.AuthInfo == oldName {
-					contextDetails.AuthInfo = newName
-					updatedContextsCount++
-				}
-			}
-			if updatedContextsCount > 0 {
-				fmt.Printf("Updated %d context(s) to reference the new user name '%s'.\n", updatedContextsCount, newName)
-			}
+		// Save the modified kubeconfig file.
+		if err := saveKubeconfig(config, resolvedKubeconfigPath); err != nil {
+			return fmt.Errorf("error saving kubeconfig to '%s' after renaming: %w", resolvedKubeconfigPath, err)
+		}
 
-		case "context":
-			// 1. Check if the old context name exists.
-			contextToRename, ok := config.Contexts[oldName]
-			if !ok {
-				return fmt.Errorf("context '%s' not found in '%s'", oldName, resolvedKubeconfigPath)
-			}
+		// General success message - specific actions already logged.
+		// fmt.Printf("Successfully completed rename operation for %s '%s' to '%s' in '%s'.\n", itemType, oldName, newName, resolvedKubeconfigPath)
+		return nil
+	},
+}
+
+// renameItem renames a cluster, user or context in the config and updates all
+// references to it. It returns messages describing the changes made.
+func renameItem(config *api.Config, itemType, oldName, newName string) ([]string, error) {
+	var messages []string
+	switch itemType {
+	case "cluster":
+		// 1. Check if the old cluster name exists.
+		clusterToRename, ok := config.Clusters[oldName]
+		if !ok {
+			return nil, fmt.Errorf("cluster '%s' not found in '%s'", oldName, resolvedKubeconfigPath)
+		}
 
-			// 2. Check if the new context name already exists.
-			if _, exists := config.Contexts[newName]; exists {
-				return fmt.Errorf("a context with the name '%s' already exists", newName)
+		// 2. Check if the new cluster name already exists.
+		if _, exists := config.Clusters[newName]; exists {
+			return nil, fmt.Errorf("a cluster with the name '%s' already exists", newName)
+		}
+
+		// 3. Perform rename for the cluster entry.
+		config.Clusters[newName] = clusterToRename
+		delete(config.Clusters, oldName)
+		messages = append(messages, fmt.Sprintf("Renamed cluster '%s' to '%s'.", oldName, newName))
+
+		// 4. Update references in all contexts.
+		updatedContextsCount := 0
+		for _, contextDetails := range config.Contexts {
+			if contextDetails.Cluster == oldName {
+				// contextDetails is a pointer, so this modifies the original.
+				contextDetails.Cluster = newName
+				updatedContextsCount++
 			}
+		}
+		if updatedContextsCount > 0 {
+			messages = append(messages, fmt.Sprintf("Updated %d context(s) to reference the new cluster name '%s'.", updatedContextsCount, newName))
+		}
+
+	case "user":
+		// 1. Check if the old user name exists.
+		userToRename, ok := config.AuthInfos[oldName]
+		if !ok {
+			return nil, fmt.Errorf("user '%s' not found in '%s'", oldName, resolvedKubeconfigPath)
+		}
 
-			// 3. Perform rename for the context entry.
-			config.Contexts[newName] = contextToRename
-			delete(config.Contexts, oldName)
-			fmt.Printf("Renamed context '%s' to '%s'.\n", oldName, newName)
+		// 2. Check if the new user name already exists.
+		if _, exists := config.AuthInfos[newName]; exists {
+			return nil, fmt.Errorf("a user with the name '%s' already exists", newName)
+		}
 
-			// 4. Update current-context field if it was the renamed context.
-			if config.CurrentContext == oldName {
-				config.CurrentContext = newName
-				fmt.Printf("Updated current-context from '%s' to '%s'.\n", oldName, newName)
+		// 3. Perform rename for the user entry.
+		config.AuthInfos[newName] = userToRename
+		delete(config.AuthInfos, oldName)
+		messages = append(messages, fmt.Sprintf("Renamed user '%s' to '%s'.", oldName, newName))
+
+		// 4. Update references in all contexts.
+		updatedContextsCount := 0
+		for _, contextDetails := range config.Contexts { // contextNameInMap not needed here for msg
+			if contextDetails.AuthInfo == oldName {
+				contextDetails.AuthInfo = newName
+				updatedContextsCount++
 			}
+		}
+		if updatedContextsCount > 0 {
+			messages = append(messages, fmt.Sprintf("Updated %d context(s) to reference the new user name '%s'.", updatedContextsCount, newName))
+		}
 
-		default:
-			return fmt.Errorf("invalid item type '%s'. Must be one of: cluster, user, context", itemType)
+	case "context":
+		// 1. Check if the old context name exists.
+		contextToRename, ok := config.Contexts[oldName]
+		if !ok {
+			return nil, fmt.Errorf("context '%s' not found in '%s'", oldName, resolvedKubeconfigPath)
 		}
 
-		// Save the modified kubeconfig file.
-		if err := saveKubeconfig(config, resolvedKubeconfigPath); err != nil {
-			return fmt.Errorf("error saving kubeconfig to '%s' after renaming: %w", resolvedKubeconfigPath, err)
+		// 2. Check if the new context name already exists.
+		if _, exists := config.Contexts[newName]; exists {
+			return nil, fmt.Errorf("a context with the name '%s' already exists", newName)
 		}
 
-		// General success message - specific actions already logged.
-		// fmt.Printf("Successfully completed rename operation for %s '%s' to '%s' in '%s'.\n", itemType, oldName, newName, resolvedKubeconfigPath)
-		return nil
-	},
+		// 3. Perform rename for the context entry.
+		config.Contexts[newName] = contextToRename
+		delete(config.Contexts, oldName)
+		messages = append(messages, fmt.Sprintf("Renamed context '%s' to '%s'.", oldName, newName))
+
+		// 4. Update current-context field if it was the renamed context.
+		if config.CurrentContext == oldName {
+			config.CurrentContext = newName
+			messages = append(messages, fmt.Sprintf("Updated current-context from '%s' to '%s'.", oldName, newName))
+		}
+
+	default:
+		return nil, fmt.Errorf("invalid item type '%s'. Must be one of: cluster, user, context", itemType)
+	}
+	return messages, nil
 }
 
 func init() {
@@ -1,9 +1,13 @@
 package cmd
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 
 	"github.com/mitchellh/go-homedir"
 	"github.com/spf13/cobra"
@@ -28,27 +32,34 @@ clusters, users, and contexts, delete specific entries, prune
 unreferenced items, and merge contexts from other kubeconfig files.`,
 	// This function runs before any subcommand's RunE, ensuring resolvedKubeconfigPath is set.
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-		var path string
 		var err error
+		resolvedKubeconfigPath, err = resolveKubeconfigPath()
+		return err
+	},
+}
 
-		if cfgFile != "" {
-			path = cfgFile
-		} else {
-			// Default to $HOME/.kube/config
-			home, homeErr := os.UserHomeDir()
-			if homeErr != nil {
-				return fmt.Errorf("failed to get user home directory: %w", homeErr)
-			}
-			path = filepath.Join(home, ".kube", "config")
-		}
+// resolveKubeconfigPath returns the kubeconfig path from the --kubeconfig flag,
+// defaulting to $HOME/.kube/config, with ~ expanded.
+func resolveKubeconfigPath() (string, error) {
+	var path string
 
-		// Expand path (e.g., ~ to actual home directory)
-		resolvedKubeconfigPath, err = homedir.Expand(path)
-		if err != nil {
-			return fmt.Errorf("error expanding path '%s': %w", path, err)
+	if cfgFile != "" {
+		path = cfgFile
+	} else {
+		// Default to $HOME/.kube/config
+		home, homeErr := os.UserHomeDir()
+		if homeErr != nil {
+			return "", fmt.Errorf("failed to get user home directory: %w", homeErr)
 		}
-		return nil
-	},
+		path = filepath.Join(home, ".kube", "config")
+	}
+
+	// Expand path (e.g., ~ to actual home directory)
+	expanded, err := homedir.Expand(path)
+	if err != nil {
+		return "", fmt.Errorf("error expanding path '%s': %w", path, err)
+	}
+	return expanded, nil
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -56,10 +67,25 @@ unreferenced items, and merge contexts from other kubeconfig files.`,
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		// Cobra already prints the error to stderr, so we just exit.
+		var exitErr *exitCodeError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
 		os.Exit(1)
 	}
 }
 
+// exitCodeError makes kedit exit with the given code, e.g. to pass on the exit
+// code of a command it ran. Commands returning it set SilenceErrors, as the
+// reason for the failure has already been reported.
+type exitCodeError struct {
+	code int
+}
+
+func (e *exitCodeError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
 func init() {
 	// Register global persistent flag for --kubeconfig
 	rootCmd.PersistentFlags().StringVarP(&cfgFile, "kubeconfig", "k", "", "Path to the kubeconfig file (default is $HOME/.kube/config)")
@@ -70,7 +96,7 @@ func init() {
 // loadKubeconfig loads the configuration from the given file path.
 // If the file does not exist, it returns a new empty, initialized config.
 func loadKubeconfig(filePath string) (*api.Config, error) {
-	config, err := clientcmd.LoadFromFile(filePath)
+	content, err := os.ReadFile(filePath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			// File doesn't exist, return a new empty config.
@@ -80,6 +106,33 @@ func loadKubeconfig(filePath string) (*api.Config, error) {
 		}
 		return nil, fmt.Errorf("failed to load kubeconfig from '%s': %w", filePath, err)
 	}
+	// Encrypted kubeconfigs are decrypted transparently.
+	if isEncryptedKubeconfig(content) {
+		if content, _, err = openEncryptedKubeconfig(filePath, content); err != nil {
+			return nil, err
+		}
+	}
+	// So are kubeconfigs with sealed fields.
+	if hasSealedFields(content) {
+		if content, _, err = openSealedFields(filePath, content); err != nil {
+			return nil, err
+		}
+	}
+	config, err := clientcmd.Load(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig from '%s': %w", filePath, err)
+	}
+	// As clientcmd.LoadFromFile does, record where each entry was loaded from,
+	// so that relative file references can be resolved.
+	for _, cluster := range config.Clusters {
+		cluster.LocationOfOrigin = filePath
+	}
+	for _, authInfo := range config.AuthInfos {
+		authInfo.LocationOfOrigin = filePath
+	}
+	for _, context := range config.Contexts {
+		context.LocationOfOrigin = filePath
+	}
 
 	// Ensure maps are initialized, though clientcmd.LoadFromFile and api.NewConfig should handle this.
 	// This is a defensive measure.
@@ -98,6 +151,13 @@ func loadKubeconfig(filePath string) (*api.Config, error) {
 // saveKubeconfig saves the configuration to the given file path.
 // It creates the parent directory if it doesn't exist.
 func saveKubeconfig(config *api.Config, filePath string) error {
+	return saveKubeconfigFrom(config, filePath, filePath)
+}
+
+// saveKubeconfigFrom saves the configuration, loaded from sourcePath, to
+// filePath. The file keeps the format, encryption and sealed fields of the
+// source kubeconfig.
+func saveKubeconfigFrom(config *api.Config, filePath, sourcePath string) error {
 	dir := filepath.Dir(filePath)
 	info, err := os.Stat(dir)
 	if err != nil {
@@ -112,8 +172,108 @@ func saveKubeconfig(config *api.Config, filePath string) error {
 		return fmt.Errorf("path '%s' exists but is not a directory", dir)
 	}
 
-	if err := clientcmd.WriteToFile(*config, filePath); err != nil {
+	existing, err := os.ReadFile(sourcePath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read kubeconfig '%s': %w", sourcePath, err)
+	}
+	// Encrypted kubeconfigs stay encrypted with the same passphrase.
+	var passphrase []byte
+	if isEncryptedKubeconfig(existing) {
+		if existing, passphrase, err = openEncryptedKubeconfig(sourcePath, existing); err != nil {
+			return err
+		}
+	}
+	// Sealed fields stay sealed with the same key.
+	var seal *fieldSeal
+	if hasSealedFields(existing) {
+		if existing, seal, err = openSealedFields(sourcePath, existing); err != nil {
+			return err
+		}
+	}
+
+	// Edit existing YAML files in place to keep comments and ordering; write
+	// new and JSON files from scratch.
+	content, preserved, err := preserveFormat(existing, config)
+	if err != nil {
+		return fmt.Errorf("failed to save kubeconfig to '%s': %w", filePath, err)
+	}
+	if preserved && bytes.Equal(content, existing) && filePath == sourcePath {
+		return nil // Nothing changed.
+	}
+	if !preserved {
+		if content, err = clientcmd.Write(*config); err != nil {
+			return fmt.Errorf("failed to save kubeconfig to '%s': %w", filePath, err)
+		}
+	}
+	if seal != nil {
+		if content, err = seal.sealFields(content); err != nil {
+			return fmt.Errorf("failed to seal fields of '%s': %w", filePath, err)
+		}
+	}
+	if passphrase != nil {
+		if content, err = encryptKubeconfig(content, passphrase); err != nil {
+			return err
+		}
+	}
+	if err := os.WriteFile(filePath, content, 0600); err != nil {
 		return fmt.Errorf("failed to save kubeconfig to '%s': %w", filePath, err)
 	}
 	return nil
 }
+
+// absolutePath expands ~ and makes path absolute.
+func absolutePath(path string) (string, error) {
+	expanded, err := homedir.Expand(path)
+	if err != nil {
+		return "", fmt.Errorf("error expanding path '%s': %w", path, err)
+	}
+	absPath, err := filepath.Abs(expanded)
+	if err != nil {
+		return "", fmt.Errorf("error resolving path '%s': %w", path, err)
+	}
+	return absPath, nil
+}
+
+// matchGlob reports whether name matches the glob pattern, where '*' matches any
+// sequence of characters (including '/', which is common in cloud-generated names)
+// and '?' matches any single character.
+func matchGlob(pattern, name string) bool {
+	var expr strings.Builder
+	expr.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			expr.WriteString(".*")
+		case '?':
+			expr.WriteString(".")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	expr.WriteString("$")
+	return regexp.MustCompile(expr.String()).MatchString(name)
+}
+
+// redactConfig returns a copy of the config that is safe to display: embedded
+// certificate data is omitted and keys, tokens, passwords, auth-provider
+// settings and exec environment values are replaced with REDACTED.
+func redactConfig(config *api.Config) (*api.Config, error) {
+	redacted := config.DeepCopy()
+	api.ShortenConfig(redacted)
+	if err := api.RedactSecrets(redacted); err != nil {
+		return nil, fmt.Errorf("failed to redact secrets: %w", err)
+	}
+	for _, authInfo := range redacted.AuthInfos {
+		if authInfo.AuthProvider != nil {
+			for key := range authInfo.AuthProvider.Config {
+				authInfo.AuthProvider.Config[key] = "REDACTED"
+			}
+		}
+		if authInfo.Exec != nil {
+			for i := range authInfo.Exec.Env {
+				authInfo.Exec.Env[i].Value = "REDACTED"
+			}
+		}
+	}
+	return redacted, nil
+}
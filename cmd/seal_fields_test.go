@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSealFieldsCommands(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "kedit-test-seal-fields-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+	keyPath := filepath.Join(tempDir, "field.key")
+	t.Setenv("KEDIT_FIELD_KEY_FILE", keyPath)
+
+	kubeconfigPath := filepath.Join(tempDir, "config")
+	err = ioutil.WriteFile(kubeconfigPath, []byte(`apiVersion: v1
+kind: Config
+current-context: ci
+clusters:
+- name: ci
+  cluster:
+    server: https://ci.example.com
+contexts:
+- name: ci
+  context:
+    cluster: ci
+    user: deployer
+users:
+# Deploys from CI.
+- name: deployer
+  user:
+    token: secret-token
+- name: cloud
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1
+      command: get-token
+      env:
+      - name: API_KEY
+        value: secret-key
+`), 0644)
+	assert.NoError(t, err)
+
+	// Test that only secret fields are encrypted and the rest stays readable.
+	t.Run("seal fields", func(t *testing.T) {
+		output := executeCommandC(t, "seal-fields", "--generate-key", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Generated a new field key in '"+keyPath+"'.")
+		assert.Contains(t, output, "Sealed 2 secret field(s) in '"+kubeconfigPath+"'.")
+
+		content, err := ioutil.ReadFile(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.NotContains(t, string(content), "secret-")
+		assert.Contains(t, string(content), "server: https://ci.example.com")
+		assert.Contains(t, string(content), "# Deploys from CI.\n- name: deployer\n  user:\n    token: ENC[AES256_GCM,data:")
+		assert.Contains(t, string(content), "name: kedit/sealed-fields")
+	})
+
+	// Test that commands open sealed fields transparently and keep unchanged ciphertexts.
+	t.Run("seal fields transparent", func(t *testing.T) {
+		before, err := ioutil.ReadFile(kubeconfigPath)
+		assert.NoError(t, err)
+
+		config, err := loadKubeconfig(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.Equal(t, "secret-token", config.AuthInfos["deployer"].Token)
+		assert.Equal(t, "secret-key", config.AuthInfos["cloud"].Exec.Env[0].Value)
+
+		executeCommandC(t, "set", "context", "ci", "--namespace", "deploy", "--kubeconfig", kubeconfigPath)
+		after, err := ioutil.ReadFile(kubeconfigPath)
+		assert.NoError(t, err)
+		tokenLine := func(content []byte) string {
+			for _, line := range strings.Split(string(content), "\n") {
+				if strings.Contains(line, "token:") {
+					return line
+				}
+			}
+			return ""
+		}
+		assert.Equal(t, tokenLine(before), tokenLine(after))
+		assert.Contains(t, string(after), "namespace: deploy")
+		assert.NotContains(t, string(after), "secret-")
+
+		config, err = loadKubeconfig(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.Equal(t, "deploy", config.Contexts["ci"].Namespace)
+	})
+
+	// Test that changes made without the key are detected.
+	t.Run("seal fields tampered", func(t *testing.T) {
+		content, err := ioutil.ReadFile(kubeconfigPath)
+		assert.NoError(t, err)
+		tampered := strings.Replace(string(content), "https://ci.example.com", "https://evil.example.com", 1)
+		tamperedPath := filepath.Join(tempDir, "tampered")
+		assert.NoError(t, ioutil.WriteFile(tamperedPath, []byte(tampered), 0600))
+
+		output := executeCommandC(t, "list", "all", "--kubeconfig", tamperedPath)
+		assert.Contains(t, output, "MAC mismatch: the kubeconfig was modified without the field key")
+	})
+
+	// Test that open-fields decrypts the fields and removes the metadata.
+	t.Run("open fields", func(t *testing.T) {
+		output := executeCommandC(t, "open-fields", "--kubeconfig", kubeconfigPath)
+		assert.Equal(t, "Opened 2 sealed field(s) in '"+kubeconfigPath+"'.", output)
+
+		content, err := ioutil.ReadFile(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.Contains(t, string(content), "token: secret-token")
+		assert.Contains(t, string(content), "value: secret-key")
+		assert.NotContains(t, string(content), "kedit/sealed-fields")
+		assert.NotContains(t, string(content), "extensions")
+
+		output = executeCommandC(t, "open-fields", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Error: '"+kubeconfigPath+"' has no sealed fields")
+	})
+}
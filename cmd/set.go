@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// setCmd represents the set command
+var setCmd = &cobra.Command{
+	Use:   "set (cluster|user|context) <name> [flags]",
+	Short: "Create or modify a cluster, user, or context",
+	Long: `Create or modify a cluster, user, or context in the kubeconfig file.
+
+If the named item does not exist, it is created. If it exists, only the
+fields given as flags are changed. Input is validated before the kubeconfig
+file is saved.`,
+}
+
+func init() {
+	rootCmd.AddCommand(setCmd)
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+var (
+	setClusterServer                string // Flag for the API server URL
+	setClusterCertificateAuthority  string // Flag for the path to the CA certificate file
+	setClusterEmbedCerts            bool   // Flag to embed the CA certificate instead of referencing the file
+	setClusterInsecureSkipTLSVerify bool   // Flag to skip verification of the server certificate
+	setClusterTLSServerName         string // Flag for the server name used to verify the server certificate
+	setClusterProxyURL              string // Flag for the proxy URL
+	setClusterDisableCompression    bool   // Flag to disable response compression
+)
+
+// setClusterCmd represents the set cluster command
+var setClusterCmd = &cobra.Command{
+	Use:   "cluster <name> [--server <url>] [--certificate-authority <file> [--embed]] [flags]",
+	Short: "Create or modify a cluster",
+	Long: `Create a cluster entry, or modify the fields of an existing one.
+
+--server sets the API server URL (required when creating a cluster).
+--certificate-authority sets the path to a PEM-encoded CA certificate;
+with --embed its contents are embedded as certificate-authority-data.
+--insecure-skip-tls-verify, --tls-server-name, --proxy-url and
+--disable-compression set the corresponding cluster fields.
+
+The server and proxy URLs and the CA certificate are validated, and
+contradictory combinations (e.g. a CA together with
+insecure-skip-tls-verify) are refused before the kubeconfig is saved.`,
+	Args:              cobra.ExactArgs(1), // Requires the cluster name
+	ValidArgsFunction: completeName("cluster"),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName := args[0]
+		flags := cmd.Flags()
+
+		if setClusterEmbedCerts && !flags.Changed("certificate-authority") {
+			return fmt.Errorf("flag --embed requires --certificate-authority")
+		}
+
+		config, err := loadKubeconfig(resolvedKubeconfigPath)
+		if err != nil {
+			return fmt.Errorf("error loading kubeconfig from '%s': %w", resolvedKubeconfigPath, err)
+		}
+
+		cluster, exists := config.Clusters[clusterName]
+		if !exists {
+			if !flags.Changed("server") {
+				return fmt.Errorf("flag --server is required when creating cluster '%s'", clusterName)
+			}
+			cluster = api.NewCluster()
+		}
+
+		if flags.Changed("server") {
+			if err := validateURL(setClusterServer, "http", "https"); err != nil {
+				return fmt.Errorf("invalid --server: %w", err)
+			}
+			cluster.Server = setClusterServer
+		}
+
+		if flags.Changed("certificate-authority") {
+			if setClusterCertificateAuthority == "" {
+				// An empty value removes the CA.
+				cluster.CertificateAuthority = ""
+				cluster.CertificateAuthorityData = nil
+			} else {
+				caPath, err := absolutePath(setClusterCertificateAuthority)
+				if err != nil {
+					return err
+				}
+				caData, err := os.ReadFile(caPath)
+				if err != nil {
+					return fmt.Errorf("failed to read certificate authority '%s': %w", caPath, err)
+				}
+				if _, err := parseCertificates(caData); err != nil {
+					return fmt.Errorf("invalid certificate authority '%s': %w", caPath, err)
+				}
+				if setClusterEmbedCerts {
+					cluster.CertificateAuthority = ""
+					cluster.CertificateAuthorityData = caData
+				} else {
+					cluster.CertificateAuthority = caPath
+					cluster.CertificateAuthorityData = nil
+				}
+			}
+		}
+
+		if flags.Changed("insecure-skip-tls-verify") {
+			cluster.InsecureSkipTLSVerify = setClusterInsecureSkipTLSVerify
+		}
+		if flags.Changed("tls-server-name") {
+			cluster.TLSServerName = setClusterTLSServerName
+		}
+		if flags.Changed("proxy-url") {
+			if setClusterProxyURL != "" {
+				if err := validateURL(setClusterProxyURL, "http", "https", "socks5"); err != nil {
+					return fmt.Errorf("invalid --proxy-url: %w", err)
+				}
+			}
+			cluster.ProxyURL = setClusterProxyURL
+		}
+		if flags.Changed("disable-compression") {
+			cluster.DisableCompression = setClusterDisableCompression
+		}
+
+		if cluster.InsecureSkipTLSVerify && (cluster.CertificateAuthority != "" || len(cluster.CertificateAuthorityData) > 0) {
+			return fmt.Errorf("cluster '%s' cannot have both a certificate authority and insecure-skip-tls-verify; unset one of them", clusterName)
+		}
+
+		config.Clusters[clusterName] = cluster
+		if err := saveKubeconfig(config, resolvedKubeconfigPath); err != nil {
+			return fmt.Errorf("error saving kubeconfig to '%s' after setting cluster '%s': %w", resolvedKubeconfigPath, clusterName, err)
+		}
+
+		if exists {
+			fmt.Printf("Updated cluster '%s' in '%s'.\n", clusterName, resolvedKubeconfigPath)
+		} else {
+			fmt.Printf("Created cluster '%s' in '%s'.\n", clusterName, resolvedKubeconfigPath)
+		}
+		return nil
+	},
+}
+
+// validateURL checks that rawURL is an absolute URL with one of the given schemes and a host.
+func validateURL(rawURL string, schemes ...string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if parsed.Host == "" {
+		return fmt.Errorf("'%s' has no host", rawURL)
+	}
+	for _, scheme := range schemes {
+		if parsed.Scheme == scheme {
+			return nil
+		}
+	}
+	return fmt.Errorf("'%s' must use one of the schemes %v", rawURL, schemes)
+}
+
+func init() {
+	setClusterCmd.Flags().StringVar(&setClusterServer, "server", "", "API server URL")
+	setClusterCmd.Flags().StringVar(&setClusterCertificateAuthority, "certificate-authority", "", "Path to a PEM-encoded CA certificate (empty to remove)")
+	setClusterCmd.Flags().BoolVar(&setClusterEmbedCerts, "embed", false, "Embed the CA certificate instead of referencing the file")
+	setClusterCmd.Flags().BoolVar(&setClusterInsecureSkipTLSVerify, "insecure-skip-tls-verify", false, "Skip verification of the server certificate")
+	setClusterCmd.Flags().StringVar(&setClusterTLSServerName, "tls-server-name", "", "Server name used to verify the server certificate")
+	setClusterCmd.Flags().StringVar(&setClusterProxyURL, "proxy-url", "", "Proxy URL (http, https or socks5)")
+	setClusterCmd.Flags().BoolVar(&setClusterDisableCompression, "disable-compression", false, "Disable response compression")
+	setCmd.AddCommand(setClusterCmd)
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func TestSetClusterCommand(t *testing.T) {
+	ca := newTestCA(t, "test-ca")
+
+	// Helper to create a kubeconfig with one cluster and a CA file next to it.
+	createKubeconfigForSetCluster := func(tempDir string) (string, string) {
+		caPath := filepath.Join(tempDir, "ca.crt")
+		err := ioutil.WriteFile(caPath, ca.certPEM, 0644)
+		assert.NoError(t, err)
+
+		kubeconfigPath := filepath.Join(tempDir, "config")
+		err = ioutil.WriteFile(kubeconfigPath, []byte(`
+apiVersion: v1
+clusters:
+- cluster:
+    server: https://cluster1
+    insecure-skip-tls-verify: true
+  name: cluster1
+contexts: []
+kind: Config
+preferences: {}
+users: []
+`), 0644)
+		assert.NoError(t, err)
+		return kubeconfigPath, caPath
+	}
+
+	// Test creating a cluster with an embedded CA.
+	t.Run("set cluster create embedded", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-set-cluster-create-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		kubeconfigPath, caPath := createKubeconfigForSetCluster(tempDir)
+
+		output := executeCommandC(t, "set", "cluster", "cluster2", "--server", "https://cluster2:6443",
+			"--certificate-authority", caPath, "--embed", "--tls-server-name", "api.cluster2",
+			"--proxy-url", "socks5://localhost:1080", "--disable-compression", "--kubeconfig", kubeconfigPath)
+		expectedOutput := "Created cluster 'cluster2' in '" + kubeconfigPath + "'."
+		assert.Equal(t, expectedOutput, output)
+
+		config, err := clientcmd.LoadFromFile(kubeconfigPath)
+		assert.NoError(t, err)
+		cluster := config.Clusters["cluster2"]
+		assert.NotNil(t, cluster)
+		assert.Equal(t, "https://cluster2:6443", cluster.Server)
+		assert.Equal(t, ca.certPEM, cluster.CertificateAuthorityData)
+		assert.Empty(t, cluster.CertificateAuthority)
+		assert.Equal(t, "api.cluster2", cluster.TLSServerName)
+		assert.Equal(t, "socks5://localhost:1080", cluster.ProxyURL)
+		assert.True(t, cluster.DisableCompression)
+	})
+
+	// Test modifying only the given fields of an existing cluster.
+	t.Run("set cluster update", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-set-cluster-update-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		kubeconfigPath, caPath := createKubeconfigForSetCluster(tempDir)
+
+		output := executeCommandC(t, "set", "cluster", "cluster1", "--insecure-skip-tls-verify=false",
+			"--certificate-authority", caPath, "--kubeconfig", kubeconfigPath)
+		expectedOutput := "Updated cluster 'cluster1' in '" + kubeconfigPath + "'."
+		assert.Equal(t, expectedOutput, output)
+
+		config, err := clientcmd.LoadFromFile(kubeconfigPath)
+		assert.NoError(t, err)
+		cluster := config.Clusters["cluster1"]
+		assert.Equal(t, "https://cluster1", cluster.Server)
+		assert.False(t, cluster.InsecureSkipTLSVerify)
+		assert.Equal(t, caPath, cluster.CertificateAuthority)
+		assert.Empty(t, cluster.CertificateAuthorityData)
+	})
+
+	// Test that contradictory combinations are refused.
+	t.Run("set cluster contradictory", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-set-cluster-contradictory-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		kubeconfigPath, caPath := createKubeconfigForSetCluster(tempDir)
+
+		output := executeCommandC(t, "set", "cluster", "cluster1", "--certificate-authority", caPath, "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Error: cluster 'cluster1' cannot have both a certificate authority and insecure-skip-tls-verify")
+
+		output = executeCommandC(t, "set", "cluster", "cluster1", "--embed", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Error: flag --embed requires --certificate-authority")
+
+		config, err := clientcmd.LoadFromFile(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.Empty(t, config.Clusters["cluster1"].CertificateAuthority)
+	})
+
+	// Test validation of URLs and PEM input.
+	t.Run("set cluster invalid input", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-set-cluster-invalid-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		kubeconfigPath, _ := createKubeconfigForSetCluster(tempDir)
+
+		output := executeCommandC(t, "set", "cluster", "cluster2", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Error: flag --server is required when creating cluster 'cluster2'")
+
+		output = executeCommandC(t, "set", "cluster", "cluster2", "--server", "cluster2:6443", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Error: invalid --server:")
+
+		output = executeCommandC(t, "set", "cluster", "cluster1", "--proxy-url", "ftp://proxy", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Error: invalid --proxy-url: 'ftp://proxy' must use one of the schemes")
+
+		notPEM := filepath.Join(tempDir, "not-a-cert")
+		assert.NoError(t, ioutil.WriteFile(notPEM, []byte("garbage"), 0644))
+		output = executeCommandC(t, "set", "cluster", "cluster2", "--server", "https://cluster2", "--certificate-authority", notPEM, "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Error: invalid certificate authority '"+notPEM+"': no PEM-encoded certificate found")
+
+		config, err := clientcmd.LoadFromFile(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.Len(t, config.Clusters, 1)
+	})
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+var (
+	setContextCluster      string // Flag for the cluster referenced by the context
+	setContextUser         string // Flag for the user referenced by the context
+	setContextNamespace    string // Flag for the default namespace of the context
+	setContextAllowMissing bool   // Flag to allow references to clusters and users that do not exist
+	setContextCurrent      bool   // Flag to make the context the current-context
+)
+
+// setContextCmd represents the set context command
+var setContextCmd = &cobra.Command{
+	Use:   "context <name> [--cluster <cluster>] [--user <user>] [--namespace <namespace>] [--current]",
+	Short: "Create or modify a context",
+	Long: `Create a context, or patch the cluster, user and namespace of an existing one.
+
+--cluster and --user must name an existing cluster and user unless
+--allow-missing is given. An empty value removes the reference.
+--current also makes the context the current-context.`,
+	Args:              cobra.ExactArgs(1), // Requires the context name
+	ValidArgsFunction: completeName("context"),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		contextName := args[0]
+		flags := cmd.Flags()
+
+		config, err := loadKubeconfig(resolvedKubeconfigPath)
+		if err != nil {
+			return fmt.Errorf("error loading kubeconfig from '%s': %w", resolvedKubeconfigPath, err)
+		}
+
+		context, exists := config.Contexts[contextName]
+		if !exists {
+			context = api.NewContext()
+		}
+
+		if flags.Changed("cluster") {
+			if _, ok := config.Clusters[setContextCluster]; !ok && setContextCluster != "" && !setContextAllowMissing {
+				return fmt.Errorf("cluster '%s' not found in '%s' (use --allow-missing to reference it anyway)", setContextCluster, resolvedKubeconfigPath)
+			}
+			context.Cluster = setContextCluster
+		}
+		if flags.Changed("user") {
+			if _, ok := config.AuthInfos[setContextUser]; !ok && setContextUser != "" && !setContextAllowMissing {
+				return fmt.Errorf("user '%s' not found in '%s' (use --allow-missing to reference it anyway)", setContextUser, resolvedKubeconfigPath)
+			}
+			context.AuthInfo = setContextUser
+		}
+		if flags.Changed("namespace") {
+			context.Namespace = setContextNamespace
+		}
+
+		if !exists && context.Cluster == "" && !setContextAllowMissing {
+			return fmt.Errorf("flag --cluster is required when creating context '%s'", contextName)
+		}
+
+		config.Contexts[contextName] = context
+		if setContextCurrent {
+			config.CurrentContext = contextName
+		}
+
+		if err := saveKubeconfig(config, resolvedKubeconfigPath); err != nil {
+			return fmt.Errorf("error saving kubeconfig to '%s' after setting context '%s': %w", resolvedKubeconfigPath, contextName, err)
+		}
+
+		if exists {
+			fmt.Printf("Updated context '%s' in '%s'.\n", contextName, resolvedKubeconfigPath)
+		} else {
+			fmt.Printf("Created context '%s' in '%s'.\n", contextName, resolvedKubeconfigPath)
+		}
+		if setContextCurrent {
+			fmt.Printf("Switched current-context to '%s'.\n", contextName)
+		}
+		return nil
+	},
+}
+
+func init() {
+	setContextCmd.Flags().StringVar(&setContextCluster, "cluster", "", "Cluster referenced by the context")
+	setContextCmd.Flags().StringVar(&setContextUser, "user", "", "User referenced by the context")
+	setContextCmd.Flags().StringVar(&setContextNamespace, "namespace", "", "Default namespace of the context")
+	setContextCmd.Flags().BoolVar(&setContextAllowMissing, "allow-missing", false, "Allow references to clusters and users that do not exist")
+	setContextCmd.Flags().BoolVar(&setContextCurrent, "current", false, "Make the context the current-context")
+	setContextCmd.RegisterFlagCompletionFunc("cluster", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return completeTargetNames("cluster", toComplete, nil)
+	})
+	setContextCmd.RegisterFlagCompletionFunc("user", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return completeTargetNames("user", toComplete, nil)
+	})
+	setCmd.AddCommand(setContextCmd)
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func TestSetContextCommand(t *testing.T) {
+	// Helper to create a kubeconfig with clusters, users and one context.
+	createKubeconfigForSetContext := func(tempDir string) string {
+		kubeconfigPath := filepath.Join(tempDir, "config")
+		err := ioutil.WriteFile(kubeconfigPath, []byte(`
+apiVersion: v1
+clusters:
+- cluster:
+    server: https://cluster1
+  name: cluster1
+- cluster:
+    server: https://cluster2
+  name: cluster2
+contexts:
+- context:
+    cluster: cluster1
+    user: user1
+    namespace: default
+  name: context1
+current-context: context1
+kind: Config
+preferences: {}
+users:
+- name: user1
+  user:
+    token: token1
+- name: user2
+  user:
+    token: token2
+`), 0644)
+		assert.NoError(t, err)
+		return kubeconfigPath
+	}
+
+	// Test creating a context and making it current.
+	t.Run("set context create current", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-set-context-create-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		kubeconfigPath := createKubeconfigForSetContext(tempDir)
+
+		output := executeCommandC(t, "set", "context", "context2", "--cluster", "cluster2", "--user", "user2",
+			"--namespace", "payments", "--current", "--kubeconfig", kubeconfigPath)
+		expectedOutput := "Created context 'context2' in '" + kubeconfigPath + "'.\nSwitched current-context to 'context2'."
+		assert.Equal(t, expectedOutput, output)
+
+		config, err := clientcmd.LoadFromFile(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.Equal(t, "context2", config.CurrentContext)
+		assert.Equal(t, "cluster2", config.Contexts["context2"].Cluster)
+		assert.Equal(t, "user2", config.Contexts["context2"].AuthInfo)
+		assert.Equal(t, "payments", config.Contexts["context2"].Namespace)
+	})
+
+	// Test patching only the given fields of an existing context.
+	t.Run("set context patch", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-set-context-patch-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		kubeconfigPath := createKubeconfigForSetContext(tempDir)
+
+		output := executeCommandC(t, "set", "context", "context1", "--user", "user2", "--kubeconfig", kubeconfigPath)
+		expectedOutput := "Updated context 'context1' in '" + kubeconfigPath + "'."
+		assert.Equal(t, expectedOutput, output)
+
+		config, err := clientcmd.LoadFromFile(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.Equal(t, "cluster1", config.Contexts["context1"].Cluster)
+		assert.Equal(t, "user2", config.Contexts["context1"].AuthInfo)
+		assert.Equal(t, "default", config.Contexts["context1"].Namespace)
+	})
+
+	// Test validation of references.
+	t.Run("set context missing references", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-set-context-missing-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		kubeconfigPath := createKubeconfigForSetContext(tempDir)
+
+		output := executeCommandC(t, "set", "context", "context2", "--cluster", "missing", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Error: cluster 'missing' not found in '"+kubeconfigPath+"' (use --allow-missing to reference it anyway)")
+
+		output = executeCommandC(t, "set", "context", "context1", "--user", "missing", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Error: user 'missing' not found in '"+kubeconfigPath+"'")
+
+		output = executeCommandC(t, "set", "context", "context2", "--user", "user1", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Error: flag --cluster is required when creating context 'context2'")
+
+		output = executeCommandC(t, "set", "context", "context2", "--cluster", "missing", "--allow-missing", "--kubeconfig", kubeconfigPath)
+		assert.Equal(t, "Created context 'context2' in '"+kubeconfigPath+"'.", output)
+
+		config, err := clientcmd.LoadFromFile(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.Equal(t, "missing", config.Contexts["context2"].Cluster)
+		assert.Equal(t, "user1", config.Contexts["context1"].AuthInfo)
+	})
+}
@@ -0,0 +1,304 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+var (
+	setUserToken                  string   // Flag for the bearer token
+	setUserTokenFile              string   // Flag for the path to a file containing the bearer token
+	setUserClientCertificate      string   // Flag for the path to the client certificate file
+	setUserClientKey              string   // Flag for the path to the client key file
+	setUserEmbed                  bool     // Flag to embed the client certificate and key instead of referencing the files
+	setUserUsername               string   // Flag for the basic auth username
+	setUserPassword               string   // Flag for the basic auth password
+	setUserExecCommand            string   // Flag for the exec plugin command
+	setUserExecArgs               []string // Flag for the exec plugin arguments
+	setUserExecEnv                []string // Flag for the exec plugin environment variables (KEY=VALUE)
+	setUserExecAPIVersion         string   // Flag for the exec plugin API version
+	setUserExecInteractiveMode    string   // Flag for the exec plugin interactive mode
+	setUserExecProvideClusterInfo bool     // Flag to pass cluster information to the exec plugin
+)
+
+// authTypeFlags groups the set user flags by the credential type they configure.
+var authTypeFlags = []struct {
+	authType string
+	flags    []string
+}{
+	{"token", []string{"token", "token-file"}},
+	{"client certificate", []string{"client-certificate", "client-key", "embed"}},
+	{"basic auth", []string{"username", "password"}},
+	{"exec", []string{"exec-command", "exec-arg", "exec-env", "exec-api-version", "exec-interactive-mode", "exec-provide-cluster-info"}},
+}
+
+// setUserCmd represents the set user command
+var setUserCmd = &cobra.Command{
+	Use:   "user <name> [credential flags]",
+	Short: "Create or modify a user",
+	Long: `Create a user entry, or modify the credentials of an existing one.
+
+Exactly one credential type can be configured per invocation:
+  token               --token <token> or --token-file <file>
+  client certificate  --client-certificate <file> --client-key <file> [--embed]
+  basic auth          --username <name> --password <password>
+  exec plugin         --exec-command <cmd> [--exec-arg <arg>]... [--exec-env KEY=VALUE]...
+                      [--exec-api-version <version>] [--exec-interactive-mode Never|IfAvailable|Always]
+                      [--exec-provide-cluster-info]
+
+When the credential type changes, the fields of all other credential types
+are cleared, so a user never ends up with ambiguous credentials. A client
+certificate is verified against its key before the kubeconfig is saved.`,
+	Args:              cobra.ExactArgs(1), // Requires the user name
+	ValidArgsFunction: completeName("user"),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		userName := args[0]
+		flags := cmd.Flags()
+
+		authType := ""
+		for _, group := range authTypeFlags {
+			for _, name := range group.flags {
+				if !flags.Changed(name) {
+					continue
+				}
+				if authType != "" && authType != group.authType {
+					return fmt.Errorf("cannot configure %s and %s credentials at the same time", authType, group.authType)
+				}
+				authType = group.authType
+			}
+		}
+		if authType == "" {
+			return fmt.Errorf("no credentials specified for user '%s'", userName)
+		}
+
+		config, err := loadKubeconfig(resolvedKubeconfigPath)
+		if err != nil {
+			return fmt.Errorf("error loading kubeconfig from '%s': %w", resolvedKubeconfigPath, err)
+		}
+
+		authInfo, exists := config.AuthInfos[userName]
+		if !exists {
+			authInfo = api.NewAuthInfo()
+		}
+		clearOtherCredentials(authInfo, authType)
+
+		switch authType {
+		case "token":
+			err = applyTokenCredentials(cmd, authInfo)
+		case "client certificate":
+			err = applyClientCertificateCredentials(cmd, authInfo)
+		case "basic auth":
+			if flags.Changed("username") {
+				authInfo.Username = setUserUsername
+			}
+			if flags.Changed("password") {
+				authInfo.Password = setUserPassword
+			}
+			if authInfo.Username == "" || authInfo.Password == "" {
+				err = fmt.Errorf("basic auth requires both --username and --password")
+			}
+		case "exec":
+			err = applyExecCredentials(cmd, authInfo)
+		}
+		if err != nil {
+			return err
+		}
+
+		config.AuthInfos[userName] = authInfo
+		if err := saveKubeconfig(config, resolvedKubeconfigPath); err != nil {
+			return fmt.Errorf("error saving kubeconfig to '%s' after setting user '%s': %w", resolvedKubeconfigPath, userName, err)
+		}
+
+		if exists {
+			fmt.Printf("Updated user '%s' (%s) in '%s'.\n", userName, authType, resolvedKubeconfigPath)
+		} else {
+			fmt.Printf("Created user '%s' (%s) in '%s'.\n", userName, authType, resolvedKubeconfigPath)
+		}
+		return nil
+	},
+}
+
+// clearOtherCredentials clears the fields of every credential type other than authType.
+func clearOtherCredentials(authInfo *api.AuthInfo, authType string) {
+	if authType != "token" {
+		authInfo.Token = ""
+		authInfo.TokenFile = ""
+	}
+	if authType != "client certificate" {
+		authInfo.ClientCertificate = ""
+		authInfo.ClientCertificateData = nil
+		authInfo.ClientKey = ""
+		authInfo.ClientKeyData = nil
+	}
+	if authType != "basic auth" {
+		authInfo.Username = ""
+		authInfo.Password = ""
+	}
+	if authType != "exec" {
+		authInfo.Exec = nil
+	}
+	// The legacy auth-provider mechanism is replaced by whatever is configured now.
+	authInfo.AuthProvider = nil
+}
+
+// applyTokenCredentials sets the token or token file of a user.
+func applyTokenCredentials(cmd *cobra.Command, authInfo *api.AuthInfo) error {
+	flags := cmd.Flags()
+	if flags.Changed("token") && flags.Changed("token-file") {
+		return fmt.Errorf("flags --token and --token-file cannot be used together")
+	}
+	if flags.Changed("token") {
+		if setUserToken == "" {
+			return fmt.Errorf("flag --token cannot be empty")
+		}
+		authInfo.Token = setUserToken
+		authInfo.TokenFile = ""
+		return nil
+	}
+	if setUserTokenFile == "" {
+		return fmt.Errorf("flag --token-file cannot be empty")
+	}
+	tokenFile, err := absolutePath(setUserTokenFile)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(tokenFile)
+	if err != nil {
+		return fmt.Errorf("failed to access token file '%s': %w", tokenFile, err)
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("token file '%s' is not a regular file", tokenFile)
+	}
+	authInfo.TokenFile = tokenFile
+	authInfo.Token = ""
+	return nil
+}
+
+// applyClientCertificateCredentials sets the client certificate and key of a user and verifies that they match.
+func applyClientCertificateCredentials(cmd *cobra.Command, authInfo *api.AuthInfo) error {
+	flags := cmd.Flags()
+	if setUserEmbed && !flags.Changed("client-certificate") && !flags.Changed("client-key") {
+		return fmt.Errorf("flag --embed requires --client-certificate or --client-key")
+	}
+	if flags.Changed("client-certificate") {
+		certData, err := setUserFile(setUserClientCertificate, &authInfo.ClientCertificate, &authInfo.ClientCertificateData)
+		if err != nil {
+			return err
+		}
+		if _, err := parseCertificates(certData); err != nil {
+			return fmt.Errorf("invalid client certificate '%s': %w", setUserClientCertificate, err)
+		}
+	}
+	if flags.Changed("client-key") {
+		if _, err := setUserFile(setUserClientKey, &authInfo.ClientKey, &authInfo.ClientKeyData); err != nil {
+			return err
+		}
+	}
+
+	hasCert := authInfo.ClientCertificate != "" || len(authInfo.ClientCertificateData) > 0
+	hasKey := authInfo.ClientKey != "" || len(authInfo.ClientKeyData) > 0
+	if hasCert != hasKey {
+		return fmt.Errorf("a client certificate and a client key must be set together")
+	}
+	if err := verifyClientKeyPair(authInfo); err != nil {
+		return fmt.Errorf("client certificate does not match client key: %w", err)
+	}
+	return nil
+}
+
+// setUserFile reads the file at path and stores it either as an absolute path
+// reference or, with --embed, as inline data. It returns the file contents.
+func setUserFile(path string, pathField *string, dataField *[]byte) ([]byte, error) {
+	absPath, err := absolutePath(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read '%s': %w", absPath, err)
+	}
+	if setUserEmbed {
+		*pathField = ""
+		*dataField = data
+	} else {
+		*pathField = absPath
+		*dataField = nil
+	}
+	return data, nil
+}
+
+// applyExecCredentials configures the exec plugin of a user.
+func applyExecCredentials(cmd *cobra.Command, authInfo *api.AuthInfo) error {
+	flags := cmd.Flags()
+	if authInfo.Exec == nil {
+		if !flags.Changed("exec-command") {
+			return fmt.Errorf("flag --exec-command is required to configure an exec plugin")
+		}
+		authInfo.Exec = &api.ExecConfig{
+			APIVersion:      "client.authentication.k8s.io/v1",
+			InteractiveMode: api.IfAvailableExecInteractiveMode,
+		}
+	}
+	exec := authInfo.Exec
+
+	if flags.Changed("exec-command") {
+		if setUserExecCommand == "" {
+			return fmt.Errorf("flag --exec-command cannot be empty")
+		}
+		exec.Command = setUserExecCommand
+	}
+	if flags.Changed("exec-arg") {
+		exec.Args = append([]string{}, setUserExecArgs...)
+	}
+	if flags.Changed("exec-env") {
+		exec.Env = nil
+		for _, entry := range setUserExecEnv {
+			name, value, ok := strings.Cut(entry, "=")
+			if !ok || name == "" {
+				return fmt.Errorf("invalid --exec-env '%s', expected KEY=VALUE", entry)
+			}
+			exec.Env = append(exec.Env, api.ExecEnvVar{Name: name, Value: value})
+		}
+	}
+	if flags.Changed("exec-api-version") {
+		switch setUserExecAPIVersion {
+		case "client.authentication.k8s.io/v1", "client.authentication.k8s.io/v1beta1":
+			exec.APIVersion = setUserExecAPIVersion
+		default:
+			return fmt.Errorf("invalid --exec-api-version '%s'. Must be one of: client.authentication.k8s.io/v1, client.authentication.k8s.io/v1beta1", setUserExecAPIVersion)
+		}
+	}
+	if flags.Changed("exec-interactive-mode") {
+		switch mode := api.ExecInteractiveMode(setUserExecInteractiveMode); mode {
+		case api.NeverExecInteractiveMode, api.IfAvailableExecInteractiveMode, api.AlwaysExecInteractiveMode:
+			exec.InteractiveMode = mode
+		default:
+			return fmt.Errorf("invalid --exec-interactive-mode '%s'. Must be one of: Never, IfAvailable, Always", setUserExecInteractiveMode)
+		}
+	}
+	if flags.Changed("exec-provide-cluster-info") {
+		exec.ProvideClusterInfo = setUserExecProvideClusterInfo
+	}
+	return nil
+}
+
+func init() {
+	setUserCmd.Flags().StringVar(&setUserToken, "token", "", "Bearer token")
+	setUserCmd.Flags().StringVar(&setUserTokenFile, "token-file", "", "Path to a file containing the bearer token")
+	setUserCmd.Flags().StringVar(&setUserClientCertificate, "client-certificate", "", "Path to the client certificate file")
+	setUserCmd.Flags().StringVar(&setUserClientKey, "client-key", "", "Path to the client key file")
+	setUserCmd.Flags().BoolVar(&setUserEmbed, "embed", false, "Embed the client certificate and key instead of referencing the files")
+	setUserCmd.Flags().StringVar(&setUserUsername, "username", "", "Username for basic auth")
+	setUserCmd.Flags().StringVar(&setUserPassword, "password", "", "Password for basic auth")
+	setUserCmd.Flags().StringVar(&setUserExecCommand, "exec-command", "", "Command of the exec credential plugin")
+	setUserCmd.Flags().StringArrayVar(&setUserExecArgs, "exec-arg", nil, "Argument of the exec credential plugin (can be repeated)")
+	setUserCmd.Flags().StringArrayVar(&setUserExecEnv, "exec-env", nil, "Environment variable KEY=VALUE for the exec credential plugin (can be repeated)")
+	setUserCmd.Flags().StringVar(&setUserExecAPIVersion, "exec-api-version", "", "API version of the exec credential plugin: client.authentication.k8s.io/v1 or v1beta1")
+	setUserCmd.Flags().StringVar(&setUserExecInteractiveMode, "exec-interactive-mode", "", "Interactive mode of the exec credential plugin (Never, IfAvailable, Always)")
+	setUserCmd.Flags().BoolVar(&setUserExecProvideClusterInfo, "exec-provide-cluster-info", false, "Pass cluster information to the exec credential plugin")
+	setCmd.AddCommand(setUserCmd)
+}
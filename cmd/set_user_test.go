@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestSetUserCommand(t *testing.T) {
+	ca := newTestCA(t, "test-ca")
+	client := newTestClientCert(t, ca, "admin", time.Now().Add(365*24*time.Hour))
+
+	// Helper to create a kubeconfig with a token user and client certificate files next to it.
+	createKubeconfigForSetUser := func(tempDir string) string {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(tempDir, "client.crt"), client.certPEM, 0644))
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(tempDir, "client.key"), client.keyPEM, 0600))
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(tempDir, "other.key"), ca.keyPEM, 0600))
+
+		kubeconfigPath := filepath.Join(tempDir, "config")
+		err := ioutil.WriteFile(kubeconfigPath, []byte(`
+apiVersion: v1
+clusters: []
+contexts: []
+kind: Config
+preferences: {}
+users:
+- name: user1
+  user:
+    token: token1
+`), 0644)
+		assert.NoError(t, err)
+		return kubeconfigPath
+	}
+
+	// Test switching a token user to an embedded client certificate.
+	t.Run("set user client certificate", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-set-user-cert-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		kubeconfigPath := createKubeconfigForSetUser(tempDir)
+
+		output := executeCommandC(t, "set", "user", "user1",
+			"--client-certificate", filepath.Join(tempDir, "client.crt"),
+			"--client-key", filepath.Join(tempDir, "client.key"), "--embed", "--kubeconfig", kubeconfigPath)
+		expectedOutput := "Updated user 'user1' (client certificate) in '" + kubeconfigPath + "'."
+		assert.Equal(t, expectedOutput, output)
+
+		config, err := clientcmd.LoadFromFile(kubeconfigPath)
+		assert.NoError(t, err)
+		user := config.AuthInfos["user1"]
+		assert.Equal(t, client.certPEM, user.ClientCertificateData)
+		assert.Equal(t, client.keyPEM, user.ClientKeyData)
+		assert.Empty(t, user.ClientCertificate)
+		// The previous token must be cleared.
+		assert.Empty(t, user.Token)
+	})
+
+	// Test that a mismatched key pair is refused.
+	t.Run("set user mismatched key", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-set-user-mismatch-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		kubeconfigPath := createKubeconfigForSetUser(tempDir)
+
+		output := executeCommandC(t, "set", "user", "user1",
+			"--client-certificate", filepath.Join(tempDir, "client.crt"),
+			"--client-key", filepath.Join(tempDir, "other.key"), "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Error: client certificate does not match client key")
+
+		config, err := clientcmd.LoadFromFile(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.Equal(t, "token1", config.AuthInfos["user1"].Token)
+	})
+
+	// Test creating a user with an exec plugin.
+	t.Run("set user exec", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-set-user-exec-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		kubeconfigPath := createKubeconfigForSetUser(tempDir)
+
+		output := executeCommandC(t, "set", "user", "eks-user", "--exec-command", "aws",
+			"--exec-arg", "eks", "--exec-arg", "get-token", "--exec-env", "AWS_PROFILE=prod",
+			"--exec-interactive-mode", "Never", "--exec-provide-cluster-info", "--kubeconfig", kubeconfigPath)
+		expectedOutput := "Created user 'eks-user' (exec) in '" + kubeconfigPath + "'."
+		assert.Equal(t, expectedOutput, output)
+
+		config, err := clientcmd.LoadFromFile(kubeconfigPath)
+		assert.NoError(t, err)
+		exec := config.AuthInfos["eks-user"].Exec
+		assert.NotNil(t, exec)
+		assert.Equal(t, "aws", exec.Command)
+		assert.Equal(t, []string{"eks", "get-token"}, exec.Args)
+		assert.Equal(t, []api.ExecEnvVar{{Name: "AWS_PROFILE", Value: "prod"}}, exec.Env)
+		assert.Equal(t, "client.authentication.k8s.io/v1", exec.APIVersion)
+		assert.Equal(t, api.NeverExecInteractiveMode, exec.InteractiveMode)
+		assert.True(t, exec.ProvideClusterInfo)
+
+		output = executeCommandC(t, "set", "user", "eks-user", "--exec-api-version", "client.authentication.k8s.io/v1beta1", "--kubeconfig", kubeconfigPath)
+		assert.Equal(t, "Updated user 'eks-user' (exec) in '"+kubeconfigPath+"'.", output)
+
+		// Only the ExecCredential versions client-go supports are accepted.
+		for _, version := range []string{"", "v1", "client.authentication.k8s.io/v1alpha1"} {
+			output = executeCommandC(t, "set", "user", "eks-user", "--exec-api-version", version, "--kubeconfig", kubeconfigPath)
+			assert.Contains(t, output, "Error: invalid --exec-api-version '"+version+"'")
+		}
+		config, err = clientcmd.LoadFromFile(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.Equal(t, "client.authentication.k8s.io/v1beta1", config.AuthInfos["eks-user"].Exec.APIVersion)
+	})
+
+	// Test switching to basic auth and to a token file.
+	t.Run("set user basic auth and token file", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-set-user-basic-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		kubeconfigPath := createKubeconfigForSetUser(tempDir)
+
+		output := executeCommandC(t, "set", "user", "user1", "--username", "admin", "--password", "secret", "--kubeconfig", kubeconfigPath)
+		assert.Equal(t, "Updated user 'user1' (basic auth) in '"+kubeconfigPath+"'.", output)
+		config, err := clientcmd.LoadFromFile(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.Equal(t, "admin", config.AuthInfos["user1"].Username)
+		assert.Empty(t, config.AuthInfos["user1"].Token)
+
+		tokenPath := filepath.Join(tempDir, "token")
+		assert.NoError(t, ioutil.WriteFile(tokenPath, []byte("file-token"), 0600))
+		output = executeCommandC(t, "set", "user", "user1", "--token-file", tokenPath, "--kubeconfig", kubeconfigPath)
+		assert.Equal(t, "Updated user 'user1' (token) in '"+kubeconfigPath+"'.", output)
+		config, err = clientcmd.LoadFromFile(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.Equal(t, tokenPath, config.AuthInfos["user1"].TokenFile)
+		assert.Empty(t, config.AuthInfos["user1"].Username)
+		assert.Empty(t, config.AuthInfos["user1"].Password)
+
+		// An empty value or a directory is not a token file.
+		output = executeCommandC(t, "set", "user", "user1", "--token-file", "", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Error: flag --token-file cannot be empty")
+		output = executeCommandC(t, "set", "user", "user1", "--token-file", tempDir, "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Error: token file '"+tempDir+"' is not a regular file")
+		config, err = clientcmd.LoadFromFile(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.Equal(t, tokenPath, config.AuthInfos["user1"].TokenFile)
+	})
+
+	// Test that mixing credential types is refused.
+	t.Run("set user ambiguous", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-set-user-ambiguous-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		kubeconfigPath := createKubeconfigForSetUser(tempDir)
+
+		output := executeCommandC(t, "set", "user", "user1", "--token", "t", "--username", "admin", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Error: cannot configure token and basic auth credentials at the same time")
+
+		output = executeCommandC(t, "set", "user", "user1", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Error: no credentials specified for user 'user1'")
+
+		output = executeCommandC(t, "set", "user", "user1", "--exec-arg", "x", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Error: flag --exec-command is required to configure an exec plugin")
+	})
+}
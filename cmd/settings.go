@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"sigs.k8s.io/yaml"
+)
+
+// keditSettings holds kedit's own configuration, as opposed to the kubeconfig it edits.
+type keditSettings struct {
+	Prune  pruneSettings  `json:"prune,omitempty"`
+	Prompt promptSettings `json:"prompt,omitempty"`
+}
+
+// pruneSettings holds the persistent configuration of the prune command.
+type pruneSettings struct {
+	// Keep lists glob patterns of context, cluster and user names that are never pruned.
+	Keep []string `json:"keep,omitempty"`
+}
+
+// promptSettings holds the persistent configuration of the prompt command.
+type promptSettings struct {
+	// Format is the default template used when --format is not given.
+	Format string `json:"format,omitempty"`
+	// Colors lists the color rules applied to the prompt; the first matching rule wins.
+	Colors []promptColorRule `json:"colors,omitempty"`
+}
+
+// promptColorRule colors the prompt when the current context matches a glob pattern.
+type promptColorRule struct {
+	Pattern string `json:"pattern"`
+	Color   string `json:"color"`
+}
+
+// keditSettingsPath returns the path of the kedit config file. It defaults to
+// kedit/config.yaml in the user's config directory and can be overridden with $KEDIT_CONFIG.
+func keditSettingsPath() (string, error) {
+	if path := os.Getenv("KEDIT_CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user config directory: %w", err)
+	}
+	return filepath.Join(dir, "kedit", "config.yaml"), nil
+}
+
+// loadKeditSettings loads the kedit config file. If the file does not exist, it returns empty settings.
+func loadKeditSettings() (*keditSettings, error) {
+	settings := &keditSettings{}
+	path, err := keditSettingsPath()
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return settings, nil
+		}
+		return nil, fmt.Errorf("failed to read kedit config from '%s': %w", path, err)
+	}
+	if err := yaml.Unmarshal(content, settings); err != nil {
+		return nil, fmt.Errorf("failed to parse kedit config '%s': %w", path, err)
+	}
+	return settings, nil
+}
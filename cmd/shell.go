@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// shellCmd represents the shell command
+var shellCmd = &cobra.Command{
+	Use:   "shell <context_name>",
+	Short: "Start a shell that uses a context without changing the kubeconfig",
+	Long: `Start $SHELL with KUBECONFIG pointing to a temporary kubeconfig that contains
+only the given context and the cluster and user it references.
+
+Switching contexts in one terminal then no longer affects the others: the
+shared kubeconfig is never modified. The temporary kubeconfig is only readable
+by you and is removed when the shell exits. KEDIT_CONTEXT is set to the name
+of the context, e.g. for use in the shell prompt.`,
+	Args:              cobra.ExactArgs(1), // Requires the context name
+	ValidArgsFunction: completeName("context"),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		contextName := args[0]
+
+		config, err := loadKubeconfig(resolvedKubeconfigPath)
+		if err != nil {
+			return fmt.Errorf("error loading kubeconfig from '%s': %w", resolvedKubeconfigPath, err)
+		}
+
+		shell := os.Getenv("SHELL")
+		if shell == "" {
+			shell = "/bin/sh"
+		}
+		fmt.Printf("Starting %s with context '%s'. Exit the shell to return.\n", shell, contextName)
+		if _, err := runIsolated(config, contextName, shell, nil); err != nil {
+			return err
+		}
+		// The exit code of an interactive shell is that of its last command, so it is not passed on.
+		fmt.Printf("Left the shell for context '%s'. Removed its temporary kubeconfig.\n", contextName)
+		return nil
+	},
+}
+
+// runCmd represents the run command
+var runCmd = &cobra.Command{
+	Use:   "run <context_name> -- <command> [args...]",
+	Short: "Run a command against a context without changing the kubeconfig",
+	Long: `Run a single command with KUBECONFIG pointing to a temporary kubeconfig that
+contains only the given context and the cluster and user it references.
+
+The temporary kubeconfig is only readable by you and is removed when the
+command exits. kedit exits with the exit code of the command.
+
+Example:
+  kedit run prod-eu -- kubectl get pods -n payments`,
+	Args: cobra.MinimumNArgs(2), // Requires the context name and the command
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return completeTargetNames("context", toComplete, nil)
+		}
+		return nil, cobra.ShellCompDirectiveDefault
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if dash := cmd.ArgsLenAtDash(); dash > 1 {
+			return fmt.Errorf("expected a single context name before '--', got %d arguments", dash)
+		}
+		contextName := args[0]
+
+		config, err := loadKubeconfig(resolvedKubeconfigPath)
+		if err != nil {
+			return fmt.Errorf("error loading kubeconfig from '%s': %w", resolvedKubeconfigPath, err)
+		}
+
+		code, err := runIsolated(config, contextName, args[1], args[2:])
+		if err != nil {
+			return err
+		}
+		if code != 0 {
+			cmd.SilenceErrors = true
+			cmd.SilenceUsage = true
+			return &exitCodeError{code: code}
+		}
+		return nil
+	},
+}
+
+// writeIsolatedKubeconfig writes a kubeconfig containing only the given context
+// to a new private temporary directory. It returns the path of the kubeconfig
+// and a function that removes it.
+func writeIsolatedKubeconfig(config *api.Config, contextName string) (string, func(), error) {
+	isolated, err := extractContexts(config, []string{contextName})
+	if err != nil {
+		return "", nil, err
+	}
+	content, err := clientcmd.Write(*isolated)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to serialize kubeconfig for context '%s': %w", contextName, err)
+	}
+
+	// os.MkdirTemp creates the directory with owner-only permissions.
+	dir, err := os.MkdirTemp("", "kedit-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+
+	path := filepath.Join(dir, "config")
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to write temporary kubeconfig '%s': %w", path, err)
+	}
+	return path, cleanup, nil
+}
+
+// runIsolated runs name with args, attached to kedit's stdin, stdout and stderr,
+// using a temporary kubeconfig for the given context. It returns the exit code
+// of the command.
+func runIsolated(config *api.Config, contextName, name string, args []string) (int, error) {
+	path, cleanup, err := writeIsolatedKubeconfig(config, contextName)
+	if err != nil {
+		return 0, err
+	}
+	defer cleanup()
+
+	c := exec.Command(name, args...)
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	c.Env = isolatedEnv(os.Environ(), path, contextName)
+
+	if err := c.Start(); err != nil {
+		return 0, fmt.Errorf("failed to run '%s': %w", name, err)
+	}
+	stop := relaySignals(func() []*os.Process { return []*os.Process{c.Process} })
+	defer stop()
+
+	if err := c.Wait(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode(), nil
+		}
+		return 0, fmt.Errorf("failed to run '%s': %w", name, err)
+	}
+	return 0, nil
+}
+
+// relaySignals keeps kedit running on Ctrl-C, which reaches the commands it
+// runs through the terminal, so that temporary kubeconfigs are still removed.
+// SIGTERM, SIGHUP (sent when the terminal is closed) and SIGQUIT are passed on
+// to the processes returned by processes, and kedit exits once they do. The
+// returned function stops relaying.
+func relaySignals(processes func() []*os.Process) func() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				if sig == os.Interrupt {
+					continue
+				}
+				for _, process := range processes() {
+					process.Signal(sig)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
+
+// isolatedEnv returns env with KUBECONFIG set to path and KEDIT_CONTEXT set to the context name.
+func isolatedEnv(env []string, path, contextName string) []string {
+	result := make([]string, 0, len(env)+2)
+	for _, entry := range env {
+		if strings.HasPrefix(entry, "KUBECONFIG=") || strings.HasPrefix(entry, "KEDIT_CONTEXT=") {
+			continue
+		}
+		result = append(result, entry)
+	}
+	return append(result, "KUBECONFIG="+path, "KEDIT_CONTEXT="+contextName)
+}
+
+func init() {
+	rootCmd.AddCommand(shellCmd)
+	rootCmd.AddCommand(runCmd)
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func TestShellAndRunCommands(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "kedit-test-shell-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	kubeconfigPath := filepath.Join(tempDir, "config")
+	err = ioutil.WriteFile(kubeconfigPath, []byte(`
+apiVersion: v1
+clusters:
+- cluster:
+    server: https://cluster1
+  name: cluster1
+- cluster:
+    server: https://cluster2
+  name: cluster2
+contexts:
+- context:
+    cluster: cluster1
+    user: user1
+  name: context1
+- context:
+    cluster: cluster2
+    user: user2
+  name: context2
+current-context: context1
+kind: Config
+preferences: {}
+users:
+- name: user1
+  user:
+    token: token1
+- name: user2
+  user:
+    token: token2
+`), 0644)
+	assert.NoError(t, err)
+
+	// Test that the command sees a private kubeconfig with only the requested context.
+	t.Run("run isolated kubeconfig", func(t *testing.T) {
+		copyPath := filepath.Join(tempDir, "seen")
+		output := executeCommandC(t, "run", "context2", "--kubeconfig", kubeconfigPath, "--",
+			"sh", "-c", `echo "$KEDIT_CONTEXT $KUBECONFIG" && cp "$KUBECONFIG" "$1" && ls -l "$KUBECONFIG"`, "sh", copyPath)
+
+		match := regexp.MustCompile(`^context2 (\S+)\n-rw-------`).FindStringSubmatch(output)
+		assert.Len(t, match, 2, output)
+		// The temporary kubeconfig is removed once the command exits.
+		_, err := os.Stat(match[1])
+		assert.True(t, os.IsNotExist(err))
+
+		config, err := clientcmd.LoadFromFile(copyPath)
+		assert.NoError(t, err)
+		assert.Equal(t, "context2", config.CurrentContext)
+		assert.Len(t, config.Contexts, 1)
+		assert.Len(t, config.Clusters, 1)
+		assert.Equal(t, "token2", config.AuthInfos["user2"].Token)
+
+		// The shared kubeconfig is untouched.
+		config, err = clientcmd.LoadFromFile(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.Equal(t, "context1", config.CurrentContext)
+	})
+
+	// Test that closing the terminal still removes the temporary kubeconfig.
+	t.Run("run hangup", func(t *testing.T) {
+		seenPath := filepath.Join(tempDir, "seen-hangup")
+		done := make(chan string)
+		go func() {
+			done <- executeCommandC(t, "run", "context1", "--kubeconfig", kubeconfigPath, "--",
+				"sh", "-c", `echo "$KUBECONFIG" > "$1" && exec sleep 10`, "sh", seenPath)
+		}()
+
+		var temporaryPath string
+		for i := 0; i < 100 && temporaryPath == ""; i++ {
+			time.Sleep(50 * time.Millisecond)
+			content, _ := ioutil.ReadFile(seenPath)
+			temporaryPath = strings.TrimSpace(string(content))
+		}
+		assert.NotEmpty(t, temporaryPath)
+		_, err := os.Stat(temporaryPath)
+		assert.NoError(t, err)
+
+		self, err := os.FindProcess(os.Getpid())
+		assert.NoError(t, err)
+		assert.NoError(t, self.Signal(syscall.SIGHUP))
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("kedit run did not exit after SIGHUP")
+		}
+		_, err = os.Stat(temporaryPath)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	// Test that the exit code is passed on and errors are reported.
+	t.Run("run exit code and errors", func(t *testing.T) {
+		resetFlags(rootCmd)
+		rootCmd.SetArgs([]string{"run", "context1", "--kubeconfig", kubeconfigPath, "--", "sh", "-c", "exit 3"})
+		err := rootCmd.Execute()
+		assert.Equal(t, &exitCodeError{code: 3}, err)
+		runCmd.SilenceErrors = false
+		runCmd.SilenceUsage = false
+
+		output := executeCommandC(t, "run", "missing", "--kubeconfig", kubeconfigPath, "--", "true")
+		assert.Contains(t, output, "Error: context 'missing' not found in '"+kubeconfigPath+"'")
+
+		output = executeCommandC(t, "run", "context1", "extra", "--kubeconfig", kubeconfigPath, "--", "true")
+		assert.Contains(t, output, "Error: expected a single context name before '--', got 2 arguments")
+	})
+
+	// Test that the shell runs $SHELL with the isolated kubeconfig.
+	t.Run("shell", func(t *testing.T) {
+		t.Setenv("SHELL", "/bin/sh")
+		output := executeCommandWithInput(t, "echo \"inside $KEDIT_CONTEXT\"\n", "shell", "context1", "--kubeconfig", kubeconfigPath)
+		expectedOutput := "Starting /bin/sh with context 'context1'. Exit the shell to return.\n" +
+			"inside context1\n" +
+			"Left the shell for context 'context1'. Removed its temporary kubeconfig."
+		assert.Equal(t, expectedOutput, output)
+	})
+}
@@ -0,0 +1,468 @@
+package cmd
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	syncWith        string // Flag for the path of the shared kubeconfig
+	syncBase        string // Flag for the path of the base snapshot
+	syncPrefer      string // Flag for resolving all conflicts in favor of local or upstream
+	syncInteractive bool   // Flag to resolve conflicts one by one
+	syncDryRun      bool   // Flag to show the changes without writing anything
+)
+
+// syncChange describes what a sync did, or would do, to one entry.
+type syncChange struct {
+	kind       string
+	name       string
+	action     string // "added", "updated", "removed" or "conflict"
+	resolution string // for conflicts: "local", "upstream" or "" if unresolved
+}
+
+// syncCmd represents the sync command
+var syncCmd = &cobra.Command{
+	Use:   "sync --with <shared_kubeconfig> [--prefer local|upstream] [--interactive] [--dry-run]",
+	Short: "Three-way sync the kubeconfig with a shared kubeconfig",
+	Long: `Bring changes from a shared (upstream) kubeconfig into the target kubeconfig.
+
+kedit records a base snapshot of the shared kubeconfig after each sync. On the
+next run, every cluster, user and context is merged three-way by name:
+
+  - entries added, changed or deleted upstream are applied locally, as long as
+    they were not changed locally;
+  - entries only present locally, and local changes to entries that did not
+    change upstream, are kept;
+  - entries changed on both sides are conflicts.
+
+Conflicts are listed with their differences and left as they are locally, and
+kedit exits non-zero. Resolve them with --prefer local or --prefer upstream, or
+one by one with --interactive. Unresolved conflicts are reported again on the
+next sync. The current-context is only changed, by clearing it, when its
+context is deleted upstream, and the shared kubeconfig is never written.
+
+On the first sync there is no base, so entries that differ are conflicts.
+The base only holds a content hash of each entry, never its credentials, and
+is stored in the kedit config directory; use --base to choose another
+location. --dry-run shows the changes and conflicts without writing anything
+or asking how to resolve them.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if syncWith == "" {
+			return fmt.Errorf("flag --with <shared_kubeconfig> is required for the sync command")
+		}
+		if syncPrefer != "" && syncPrefer != "local" && syncPrefer != "upstream" {
+			return fmt.Errorf("invalid --prefer '%s'. Must be one of: local, upstream", syncPrefer)
+		}
+		if syncPrefer != "" && syncInteractive {
+			return fmt.Errorf("flags --prefer and --interactive cannot be used together")
+		}
+
+		sharedPath, err := absolutePath(syncWith)
+		if err != nil {
+			return err
+		}
+		// A missing shared kubeconfig must not look like one whose entries were all deleted.
+		if _, err := os.Stat(sharedPath); err != nil {
+			return fmt.Errorf("failed to access shared kubeconfig '%s': %w", sharedPath, err)
+		}
+		upstream, err := loadKubeconfig(sharedPath)
+		if err != nil {
+			return fmt.Errorf("failed to load shared kubeconfig from '%s': %w", sharedPath, err)
+		}
+		// File references are relative to the shared kubeconfig.
+		if err := clientcmd.ResolveLocalPaths(upstream); err != nil {
+			return fmt.Errorf("error resolving file references in '%s': %w", sharedPath, err)
+		}
+
+		localPath, err := absolutePath(resolvedKubeconfigPath)
+		if err != nil {
+			return err
+		}
+		local, err := loadKubeconfig(localPath)
+		if err != nil {
+			return fmt.Errorf("error loading kubeconfig from '%s': %w", localPath, err)
+		}
+
+		basePath := syncBase
+		if basePath == "" {
+			if basePath, err = syncBasePath(localPath, sharedPath); err != nil {
+				return err
+			}
+		}
+		base, baseExists, err := loadSyncBase(basePath)
+		if err != nil {
+			return err
+		}
+		if !baseExists {
+			fmt.Printf("No sync base found for '%s'; entries that differ are treated as conflicts.\n", sharedPath)
+		}
+
+		// A dry run only reports conflicts, it does not ask how to resolve them.
+		resolver := &syncResolver{prefer: syncPrefer, interactive: syncInteractive && !syncDryRun, reader: bufio.NewReader(os.Stdin)}
+		currentContext := local.CurrentContext
+		_, hadCurrentContext := local.Contexts[currentContext]
+		newBase, changes, err := syncConfigs(local, upstream, base, resolver)
+		if err != nil {
+			return err
+		}
+		if _, ok := local.Contexts[currentContext]; hadCurrentContext && !ok {
+			local.CurrentContext = ""
+			if syncDryRun {
+				fmt.Printf("Would clear current-context, which points to the context '%s' deleted upstream.\n", currentContext)
+			} else {
+				fmt.Printf("Cleared current-context, which pointed to the context '%s' deleted upstream.\n", currentContext)
+			}
+		}
+
+		counts := make(map[string]int)
+		for _, change := range changes {
+			key := change.action
+			if change.action == "conflict" && change.resolution == "" {
+				key = "unresolved"
+			}
+			counts[key]++
+		}
+
+		if syncDryRun {
+			fmt.Printf("Dry run: %d added, %d updated, %d removed, %d conflict(s). No changes made.\n",
+				counts["added"], counts["updated"], counts["removed"], counts["conflict"]+counts["unresolved"])
+			return nil
+		}
+
+		if counts["added"]+counts["updated"]+counts["removed"]+counts["conflict"] > 0 {
+			if err := saveKubeconfig(local, localPath); err != nil {
+				return fmt.Errorf("error saving kubeconfig to '%s' after sync: %w", localPath, err)
+			}
+		}
+		if err := writeSyncBase(newBase, basePath); err != nil {
+			return err
+		}
+
+		fmt.Printf("Synced '%s' with '%s': %d added, %d updated, %d removed, %d conflict(s) resolved, %d unresolved.\n",
+			localPath, sharedPath, counts["added"], counts["updated"], counts["removed"], counts["conflict"], counts["unresolved"])
+		if counts["unresolved"] > 0 {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%d conflict(s) left unresolved; re-run with --prefer local|upstream or --interactive to resolve them", counts["unresolved"])
+		}
+		return nil
+	},
+}
+
+// syncConfigs merges upstream into local three-way, using base as the common
+// ancestor. local is modified in place. It returns the new base to record and
+// the changes made.
+func syncConfigs(local, upstream *api.Config, base *syncBaseSnapshot, resolver *syncResolver) (*syncBaseSnapshot, []syncChange, error) {
+	newBase := newSyncBaseSnapshot()
+
+	clusterChanges, err := syncEntries("cluster", local.Clusters, upstream.Clusters, base.Clusters, newBase.Clusters, resolver)
+	if err != nil {
+		return nil, nil, err
+	}
+	userChanges, err := syncEntries("user", local.AuthInfos, upstream.AuthInfos, base.Users, newBase.Users, resolver)
+	if err != nil {
+		return nil, nil, err
+	}
+	contextChanges, err := syncEntries("context", local.Contexts, upstream.Contexts, base.Contexts, newBase.Contexts, resolver)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	changes := append(append(clusterChanges, userChanges...), contextChanges...)
+	return newBase, changes, nil
+}
+
+// syncEntries merges the entries of one kind by name. local is updated in
+// place. base holds the content hashes of the entries at the last sync, and
+// newBase receives the hashes to record as the new base: that of the upstream
+// entry once both sides agree, or the old one while a conflict is unresolved.
+func syncEntries[V any](kind string, local, upstream map[string]V, base, newBase map[string]string, resolver *syncResolver) ([]syncChange, error) {
+	names := make(map[string]bool)
+	for _, entries := range []map[string]V{local, upstream} {
+		for name := range entries {
+			names[name] = true
+		}
+	}
+	for name := range base {
+		names[name] = true
+	}
+
+	var changes []syncChange
+	for _, name := range sortedKeys(names) {
+		localEntry, inLocal := local[name]
+		upstreamEntry, inUpstream := upstream[name]
+		baseHash, inBase := base[name]
+
+		localFields, err := optionalContentFields(localEntry, inLocal)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compare %s '%s': %w", kind, name, err)
+		}
+		upstreamFields, err := optionalContentFields(upstreamEntry, inUpstream)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compare %s '%s': %w", kind, name, err)
+		}
+		localHash, upstreamHash := hashContentFields(localFields), hashContentFields(upstreamFields)
+
+		takeUpstream := func() string {
+			switch {
+			case !inUpstream:
+				delete(local, name)
+				return "removed"
+			case inLocal:
+				local[name] = upstreamEntry
+				return "updated"
+			default:
+				local[name] = upstreamEntry
+				return "added"
+			}
+		}
+		if inUpstream {
+			newBase[name] = upstreamHash
+		}
+
+		switch {
+		case localHash == upstreamHash:
+			// Both sides agree.
+		case localHash == baseHash:
+			// Only upstream changed.
+			change := syncChange{kind: kind, name: name, action: takeUpstream()}
+			printSyncChange(change)
+			changes = append(changes, change)
+		case upstreamHash == baseHash:
+			// Only local changed.
+		default:
+			resolution, err := resolver.resolve(kind, name, describeSyncConflict(inLocal, inUpstream, inBase), diffFields(localFields, upstreamFields))
+			if err != nil {
+				return nil, err
+			}
+			switch resolution {
+			case "upstream":
+				takeUpstream()
+			case "":
+				// Keep the old base, so the conflict is reported again next time.
+				delete(newBase, name)
+				if inBase {
+					newBase[name] = baseHash
+				}
+			}
+			change := syncChange{kind: kind, name: name, action: "conflict", resolution: resolution}
+			printSyncChange(change)
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
+
+// hashContentFields returns the hex SHA-256 of the fields of an entry, or ""
+// for an entry that does not exist.
+func hashContentFields(fields map[string]string) string {
+	if fields == nil {
+		return ""
+	}
+	// json.Marshal sorts the keys, so equal fields always hash the same.
+	content, _ := json.Marshal(fields)
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// optionalContentFields returns the fields of entry, or nil if it does not exist.
+func optionalContentFields(entry interface{}, exists bool) (map[string]string, error) {
+	if !exists {
+		return nil, nil
+	}
+	return contentFields(entry)
+}
+
+// describeSyncConflict explains why an entry is in conflict.
+func describeSyncConflict(inLocal, inUpstream, inBase bool) string {
+	switch {
+	case !inLocal:
+		return "was deleted locally but changed upstream"
+	case !inUpstream:
+		return "was changed locally but deleted upstream"
+	case !inBase:
+		return "differs between local and upstream"
+	default:
+		return "was changed both locally and upstream"
+	}
+}
+
+// printSyncChange prints a change made, or with --dry-run one that would be made, by sync.
+func printSyncChange(change syncChange) {
+	verbs := map[string]string{"added": "Added", "updated": "Updated", "removed": "Removed"}
+	if syncDryRun {
+		verbs = map[string]string{"added": "Would add", "updated": "Would update", "removed": "Would remove"}
+	}
+	switch change.action {
+	case "added", "updated":
+		fmt.Printf("%s %s '%s' from upstream.\n", verbs[change.action], change.kind, change.name)
+	case "removed":
+		fmt.Printf("%s %s '%s' (deleted upstream).\n", verbs[change.action], change.kind, change.name)
+	case "conflict":
+		switch change.resolution {
+		case "local":
+			fmt.Printf("Kept local %s '%s' (conflict resolved in favor of local).\n", change.kind, change.name)
+		case "upstream":
+			fmt.Printf("Took upstream %s '%s' (conflict resolved in favor of upstream).\n", change.kind, change.name)
+		default:
+			fmt.Printf("Left %s '%s' unchanged (conflict unresolved).\n", change.kind, change.name)
+		}
+	}
+}
+
+// syncResolver decides how conflicts are resolved.
+type syncResolver struct {
+	prefer      string
+	interactive bool
+	reader      *bufio.Reader
+}
+
+// resolve returns "local", "upstream", or "" to leave the conflict unresolved.
+// The conflict is always printed with its differences from local to upstream.
+func (r *syncResolver) resolve(kind, name, description string, diff []string) (string, error) {
+	fmt.Printf("Conflict: %s '%s' %s:\n", kind, name, description)
+	for _, line := range diff {
+		fmt.Println(line)
+	}
+	if !r.interactive {
+		return r.prefer, nil
+	}
+
+	fmt.Print("Keep [l]ocal, take [u]pstream or [s]kip? [s]: ")
+	line, err := r.reader.ReadString('\n')
+	if err != nil && line == "" {
+		// No input (e.g. EOF): leave the conflict unresolved.
+		fmt.Println()
+		return "", nil
+	}
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "l", "local":
+		return "local", nil
+	case "u", "upstream":
+		return "upstream", nil
+	case "", "s", "skip":
+		return "", nil
+	default:
+		return "", fmt.Errorf("invalid answer '%s'", strings.TrimSpace(line))
+	}
+}
+
+// syncBasePath returns the default location of the base snapshot for syncing
+// localPath with sharedPath, in the kedit config directory.
+func syncBasePath(localPath, sharedPath string) (string, error) {
+	settingsPath, err := keditSettingsPath()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(localPath + "\n" + sharedPath))
+	return filepath.Join(filepath.Dir(settingsPath), "sync", hex.EncodeToString(sum[:8])+".yaml"), nil
+}
+
+// syncBaseKind identifies a base snapshot file.
+const syncBaseKind = "KeditSyncBase"
+
+// syncBaseSnapshot is the base recorded after a sync. It only holds a content
+// hash per entry, which is all the three-way merge needs, so that no
+// credentials of the shared kubeconfig are stored in clear text.
+type syncBaseSnapshot struct {
+	Kind     string            `json:"kind"`
+	Clusters map[string]string `json:"clusters,omitempty"`
+	Users    map[string]string `json:"users,omitempty"`
+	Contexts map[string]string `json:"contexts,omitempty"`
+}
+
+// newSyncBaseSnapshot returns an empty base snapshot.
+func newSyncBaseSnapshot() *syncBaseSnapshot {
+	return &syncBaseSnapshot{
+		Kind:     syncBaseKind,
+		Clusters: make(map[string]string),
+		Users:    make(map[string]string),
+		Contexts: make(map[string]string),
+	}
+}
+
+// loadSyncBase loads the base snapshot and reports whether it exists. A base
+// written by an older kedit, which is a full copy of the shared kubeconfig,
+// is reduced to its hashes and replaced by them on the next sync.
+func loadSyncBase(path string) (*syncBaseSnapshot, bool, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return newSyncBaseSnapshot(), false, nil
+		}
+		return nil, false, fmt.Errorf("failed to read sync base from '%s': %w", path, err)
+	}
+	base := newSyncBaseSnapshot()
+	if err := yaml.Unmarshal(content, base); err == nil && base.Kind == syncBaseKind {
+		for _, hashes := range []*map[string]string{&base.Clusters, &base.Users, &base.Contexts} {
+			if *hashes == nil {
+				*hashes = make(map[string]string)
+			}
+		}
+		return base, true, nil
+	}
+
+	legacy, err := clientcmd.Load(content)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to parse sync base '%s': %w", path, err)
+	}
+	base = newSyncBaseSnapshot()
+	if err := hashEntries(legacy.Clusters, base.Clusters); err != nil {
+		return nil, false, err
+	}
+	if err := hashEntries(legacy.AuthInfos, base.Users); err != nil {
+		return nil, false, err
+	}
+	if err := hashEntries(legacy.Contexts, base.Contexts); err != nil {
+		return nil, false, err
+	}
+	return base, true, nil
+}
+
+// hashEntries stores the content hash of every entry in hashes.
+func hashEntries[V any](entries map[string]V, hashes map[string]string) error {
+	for name, entry := range entries {
+		fields, err := contentFields(entry)
+		if err != nil {
+			return fmt.Errorf("failed to read sync base entry '%s': %w", name, err)
+		}
+		hashes[name] = hashContentFields(fields)
+	}
+	return nil
+}
+
+// writeSyncBase writes the base snapshot with owner-only permissions.
+func writeSyncBase(base *syncBaseSnapshot, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create directory '%s': %w", filepath.Dir(path), err)
+	}
+	content, err := yaml.Marshal(base)
+	if err != nil {
+		return fmt.Errorf("failed to serialize sync base: %w", err)
+	}
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		return fmt.Errorf("failed to write sync base to '%s': %w", path, err)
+	}
+	return nil
+}
+
+func init() {
+	syncCmd.Flags().StringVar(&syncWith, "with", "", "Path to the shared kubeconfig (required)")
+	syncCmd.Flags().StringVar(&syncBase, "base", "", "Path of the base snapshot (default: in the kedit config directory)")
+	syncCmd.Flags().StringVar(&syncPrefer, "prefer", "", "Resolve all conflicts in favor of 'local' or 'upstream'")
+	syncCmd.Flags().BoolVarP(&syncInteractive, "interactive", "i", false, "Resolve conflicts one by one")
+	syncCmd.Flags().BoolVar(&syncDryRun, "dry-run", false, "Show the changes without writing anything")
+	rootCmd.AddCommand(syncCmd)
+}
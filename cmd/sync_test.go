@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncCommand(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "kedit-test-sync-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+	t.Setenv("KEDIT_CONFIG", filepath.Join(tempDir, "kedit", "config.yaml"))
+
+	localPath := filepath.Join(tempDir, "config")
+	err = ioutil.WriteFile(localPath, []byte(`
+apiVersion: v1
+clusters:
+- cluster:
+    server: https://shared
+  name: shared
+- cluster:
+    server: https://mine
+  name: mine
+contexts:
+- context:
+    cluster: mine
+    user: me
+  name: mine
+current-context: mine
+kind: Config
+preferences: {}
+users:
+- name: me
+  user:
+    token: my-token
+`), 0644)
+	assert.NoError(t, err)
+
+	sharedPath := filepath.Join(tempDir, "shared.yaml")
+	writeShared := func(content string) {
+		err := ioutil.WriteFile(sharedPath, []byte(content), 0644)
+		assert.NoError(t, err)
+	}
+	writeShared(`
+apiVersion: v1
+clusters:
+- cluster:
+    server: https://shared
+  name: shared
+- cluster:
+    server: https://team
+  name: team
+contexts:
+- context:
+    cluster: team
+    user: team-user
+  name: team
+kind: Config
+users:
+- name: team-user
+  user:
+    token: team-token
+`)
+
+	// Test that the first sync adds upstream entries and keeps local-only ones.
+	t.Run("sync first", func(t *testing.T) {
+		output := executeCommandC(t, "sync", "--with", sharedPath, "--kubeconfig", localPath)
+		assert.Contains(t, output, "No sync base found for '"+sharedPath+"'")
+		assert.Contains(t, output, "Added cluster 'team' from upstream.")
+		assert.Contains(t, output, "Added user 'team-user' from upstream.")
+		assert.Contains(t, output, "Added context 'team' from upstream.")
+		assert.Contains(t, output, "Synced '"+localPath+"' with '"+sharedPath+"': 3 added, 0 updated, 0 removed, 0 conflict(s) resolved, 0 unresolved.")
+
+		config, err := loadKubeconfig(localPath)
+		assert.NoError(t, err)
+		assert.Contains(t, config.Clusters, "mine")
+		assert.Contains(t, config.Clusters, "team")
+		assert.Equal(t, "team-token", config.AuthInfos["team-user"].Token)
+		assert.Equal(t, "mine", config.CurrentContext)
+
+		// The base only records content hashes, never credentials.
+		basePath, err := syncBasePath(localPath, sharedPath)
+		assert.NoError(t, err)
+		base, err := os.ReadFile(basePath)
+		assert.NoError(t, err)
+		assert.Contains(t, string(base), "kind: "+syncBaseKind)
+		assert.NotContains(t, string(base), "team-token")
+	})
+
+	// Test that upstream changes and deletions are applied and local changes kept.
+	t.Run("sync upstream changes", func(t *testing.T) {
+		config, err := loadKubeconfig(localPath)
+		assert.NoError(t, err)
+		config.Contexts["team"].Namespace = "local-ns"
+		assert.NoError(t, saveKubeconfig(config, localPath))
+
+		writeShared(`
+apiVersion: v1
+clusters:
+- cluster:
+    server: https://team.example.com
+  name: team
+contexts:
+- context:
+    cluster: team
+    user: team-user
+  name: team
+kind: Config
+users:
+- name: team-user
+  user:
+    token: team-token
+`)
+		output := executeCommandC(t, "sync", "--with", sharedPath, "--kubeconfig", localPath)
+		assert.Contains(t, output, "Removed cluster 'shared' (deleted upstream).")
+		assert.Contains(t, output, "Updated cluster 'team' from upstream.")
+		assert.Contains(t, output, "1 updated, 1 removed, 0 conflict(s) resolved, 0 unresolved.")
+
+		config, err = loadKubeconfig(localPath)
+		assert.NoError(t, err)
+		assert.NotContains(t, config.Clusters, "shared")
+		assert.Equal(t, "https://team.example.com", config.Clusters["team"].Server)
+		assert.Equal(t, "local-ns", config.Contexts["team"].Namespace)
+	})
+
+	// Test that entries changed on both sides are conflicts until resolved.
+	t.Run("sync conflicts", func(t *testing.T) {
+		writeShared(`
+apiVersion: v1
+clusters:
+- cluster:
+    server: https://team.example.com
+  name: team
+contexts:
+- context:
+    cluster: team
+    user: team-user
+    namespace: upstream-ns
+  name: team
+kind: Config
+users:
+- name: team-user
+  user:
+    token: team-token
+`)
+		output := executeCommandC(t, "sync", "--with", sharedPath, "--kubeconfig", localPath, "--dry-run")
+		assert.Contains(t, output, "Conflict: context 'team' was changed both locally and upstream:\n    namespace: 'local-ns' -> 'upstream-ns'")
+		assert.Contains(t, output, "No changes made.")
+
+		// A dry run does not ask how to resolve conflicts.
+		output = executeCommandC(t, "sync", "--with", sharedPath, "--kubeconfig", localPath, "--dry-run", "--interactive")
+		assert.Contains(t, output, "Conflict: context 'team' was changed both locally and upstream:")
+		assert.NotContains(t, output, "Keep [l]ocal, take [u]pstream or [s]kip?")
+		assert.Contains(t, output, "No changes made.")
+
+		output = executeCommandC(t, "sync", "--with", sharedPath, "--kubeconfig", localPath)
+		assert.Contains(t, output, "Left context 'team' unchanged (conflict unresolved).")
+		assert.Contains(t, output, "Error: 1 conflict(s) left unresolved")
+
+		output = executeCommandWithInput(t, "u\n", "sync", "--with", sharedPath, "--kubeconfig", localPath, "--interactive")
+		assert.Contains(t, output, "Keep [l]ocal, take [u]pstream or [s]kip? [s]: ")
+		assert.Contains(t, output, "Took upstream context 'team' (conflict resolved in favor of upstream).")
+
+		config, err := loadKubeconfig(localPath)
+		assert.NoError(t, err)
+		assert.Equal(t, "upstream-ns", config.Contexts["team"].Namespace)
+
+		output = executeCommandC(t, "sync", "--with", sharedPath, "--kubeconfig", localPath, "--prefer", "local")
+		assert.Contains(t, output, "0 added, 0 updated, 0 removed, 0 conflict(s) resolved, 0 unresolved.")
+	})
+
+	// Test that deleting the current context upstream clears the current-context.
+	t.Run("sync deleted current context", func(t *testing.T) {
+		config, err := loadKubeconfig(localPath)
+		assert.NoError(t, err)
+		config.CurrentContext = "team"
+		assert.NoError(t, saveKubeconfig(config, localPath))
+
+		writeShared(`
+apiVersion: v1
+clusters:
+- cluster:
+    server: https://team.example.com
+  name: team
+kind: Config
+users:
+- name: team-user
+  user:
+    token: team-token
+`)
+		output := executeCommandC(t, "sync", "--with", sharedPath, "--kubeconfig", localPath, "--dry-run")
+		assert.Contains(t, output, "Would clear current-context, which points to the context 'team' deleted upstream.")
+
+		output = executeCommandC(t, "sync", "--with", sharedPath, "--kubeconfig", localPath)
+		assert.Contains(t, output, "Removed context 'team' (deleted upstream).")
+		assert.Contains(t, output, "Cleared current-context, which pointed to the context 'team' deleted upstream.")
+
+		config, err = loadKubeconfig(localPath)
+		assert.NoError(t, err)
+		assert.NotContains(t, config.Contexts, "team")
+		assert.Empty(t, config.CurrentContext)
+	})
+}
+
+func TestSyncLegacyBase(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "kedit-test-sync-legacy-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	content := `
+apiVersion: v1
+clusters:
+- cluster:
+    server: https://team
+  name: team
+kind: Config
+users:
+- name: team-user
+  user:
+    token: team-token
+`
+	localPath := filepath.Join(tempDir, "config")
+	sharedPath := filepath.Join(tempDir, "shared.yaml")
+	basePath := filepath.Join(tempDir, "base.yaml")
+	for _, path := range []string{localPath, sharedPath, basePath} {
+		assert.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+	}
+	// The user changed the token locally; upstream did not change.
+	config, err := loadKubeconfig(localPath)
+	assert.NoError(t, err)
+	config.AuthInfos["team-user"].Token = "my-token"
+	assert.NoError(t, saveKubeconfig(config, localPath))
+
+	// A base holding a full kubeconfig is still used as the common ancestor...
+	output := executeCommandC(t, "sync", "--with", sharedPath, "--base", basePath, "--kubeconfig", localPath)
+	assert.NotContains(t, output, "Conflict")
+	assert.Contains(t, output, "0 added, 0 updated, 0 removed, 0 conflict(s) resolved, 0 unresolved.")
+
+	// ...and replaced by its hashes.
+	base, err := os.ReadFile(basePath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(base), "kind: "+syncBaseKind)
+	assert.NotContains(t, string(base), "team-token")
+}
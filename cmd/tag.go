@@ -0,0 +1,311 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// contextMetadataExtension is the name of the context extension holding the tags and note of a context.
+const contextMetadataExtension = "kedit/metadata"
+
+// contextMetadata is the content of the context metadata extension.
+type contextMetadata struct {
+	Tags map[string]string `json:"tags,omitempty"`
+	Note string            `json:"note,omitempty"`
+}
+
+// tagCmd represents the tag command
+var tagCmd = &cobra.Command{
+	Use:   "tag context <name|pattern> <key>=<value>... [<key>-]...",
+	Short: "Set or remove tags on contexts",
+	Long: `Set or remove tags on every context matching the name or glob pattern ('*'
+matches any characters, '?' a single one).
+
+<key>=<value> sets a tag and <key>- removes it. Tags are stored in the
+'` + contextMetadataExtension + `' extension of the context, which kubectl and other tools
+ignore, and are kept when the context is renamed or merged.
+
+Use 'kedit list context --tag <key>=<value>' to select contexts by tag and
+'kedit list context -o wide' to show them.
+
+Example:
+  kedit tag context 'prod-*' env=prod team=payments`,
+	Args: cobra.MinimumNArgs(3), // Requires the type, the context and at least one tag
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		switch len(args) {
+		case 0:
+			return completeWords([]string{"context"}, toComplete), cobra.ShellCompDirectiveNoFileComp
+		case 1:
+			return completeTargetNames("context", toComplete, nil)
+		default:
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if args[0] != "context" {
+			return fmt.Errorf("invalid type '%s'. Only contexts can be tagged", args[0])
+		}
+		set := make(map[string]string)
+		var remove []string
+		for _, arg := range args[2:] {
+			if key, ok := strings.CutSuffix(arg, "-"); ok && !strings.Contains(arg, "=") {
+				if err := validateTagKey(key); err != nil {
+					return err
+				}
+				remove = append(remove, key)
+				continue
+			}
+			key, value, err := parseTag(arg)
+			if err != nil {
+				return err
+			}
+			set[key] = value
+		}
+
+		config, err := loadKubeconfig(resolvedKubeconfigPath)
+		if err != nil {
+			return fmt.Errorf("error loading kubeconfig from '%s': %w", resolvedKubeconfigPath, err)
+		}
+		names := matchingContexts(config, args[1])
+		if len(names) == 0 {
+			return fmt.Errorf("no contexts in '%s' match '%s'", resolvedKubeconfigPath, args[1])
+		}
+
+		for _, name := range names {
+			context := config.Contexts[name]
+			metadata, err := readContextMetadata(context)
+			if err != nil {
+				return fmt.Errorf("context '%s': %w", name, err)
+			}
+			if metadata.Tags == nil {
+				metadata.Tags = make(map[string]string)
+			}
+			for key, value := range set {
+				metadata.Tags[key] = value
+			}
+			for _, key := range remove {
+				delete(metadata.Tags, key)
+			}
+			if err := writeContextMetadata(context, metadata); err != nil {
+				return fmt.Errorf("context '%s': %w", name, err)
+			}
+		}
+
+		if err := saveKubeconfig(config, resolvedKubeconfigPath); err != nil {
+			return fmt.Errorf("error saving kubeconfig to '%s' after tagging contexts: %w", resolvedKubeconfigPath, err)
+		}
+		for _, name := range names {
+			metadata, _ := readContextMetadata(config.Contexts[name])
+			if len(metadata.Tags) == 0 {
+				fmt.Printf("Context '%s' has no tags.\n", name)
+			} else {
+				fmt.Printf("Context '%s' is tagged %s.\n", name, formatTags(metadata.Tags))
+			}
+		}
+		return nil
+	},
+}
+
+// noteCmd represents the note command
+var noteCmd = &cobra.Command{
+	Use:   "note context <name> <text>",
+	Short: "Set or remove the note of a context",
+	Long: `Set a free-form note on a context, such as who owns the cluster or when it
+can be deleted. An empty text removes the note.
+
+The note is stored in the '` + contextMetadataExtension + `' extension of the context, next
+to its tags, and is shown by 'kedit list context -o wide'.
+
+Example:
+  kedit note context prod-eu "Owned by the payments team, ask in #payments-oncall"`,
+	Args: cobra.ExactArgs(3), // Requires the type, the context and the text
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		switch len(args) {
+		case 0:
+			return completeWords([]string{"context"}, toComplete), cobra.ShellCompDirectiveNoFileComp
+		case 1:
+			return completeTargetNames("context", toComplete, nil)
+		default:
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if args[0] != "context" {
+			return fmt.Errorf("invalid type '%s'. Notes can only be set on contexts", args[0])
+		}
+		name, note := args[1], strings.TrimSpace(args[2])
+
+		config, err := loadKubeconfig(resolvedKubeconfigPath)
+		if err != nil {
+			return fmt.Errorf("error loading kubeconfig from '%s': %w", resolvedKubeconfigPath, err)
+		}
+		context, ok := config.Contexts[name]
+		if !ok {
+			return fmt.Errorf("context '%s' not found in '%s'", name, resolvedKubeconfigPath)
+		}
+		metadata, err := readContextMetadata(context)
+		if err != nil {
+			return fmt.Errorf("context '%s': %w", name, err)
+		}
+		metadata.Note = note
+		if err := writeContextMetadata(context, metadata); err != nil {
+			return fmt.Errorf("context '%s': %w", name, err)
+		}
+
+		if err := saveKubeconfig(config, resolvedKubeconfigPath); err != nil {
+			return fmt.Errorf("error saving kubeconfig to '%s' after setting the note: %w", resolvedKubeconfigPath, err)
+		}
+		if note == "" {
+			fmt.Printf("Removed the note of context '%s'.\n", name)
+		} else {
+			fmt.Printf("Set the note of context '%s'.\n", name)
+		}
+		return nil
+	},
+}
+
+// matchingContexts returns the sorted names of the contexts matching a name or glob pattern.
+func matchingContexts(config *api.Config, pattern string) []string {
+	var names []string
+	for _, name := range sortedKeys(config.Contexts) {
+		if name == pattern || matchGlob(pattern, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// parseTag splits a "<key>=<value>" argument.
+func parseTag(arg string) (string, string, error) {
+	key, value, ok := strings.Cut(arg, "=")
+	if !ok {
+		return "", "", fmt.Errorf("invalid tag '%s'. Expected <key>=<value>", arg)
+	}
+	if err := validateTagKey(key); err != nil {
+		return "", "", err
+	}
+	return key, value, nil
+}
+
+// validateTagKey rejects keys that could not be given back on the command line.
+func validateTagKey(key string) error {
+	if key == "" || strings.ContainsAny(key, "=, \t") {
+		return fmt.Errorf("invalid tag key '%s'", key)
+	}
+	return nil
+}
+
+// parseTagSelectors parses the "<key>=<value>" arguments of list --tag.
+func parseTagSelectors(args []string) (map[string]string, error) {
+	selectors := make(map[string]string, len(args))
+	for _, arg := range args {
+		key, value, err := parseTag(arg)
+		if err != nil {
+			return nil, err
+		}
+		selectors[key] = value
+	}
+	return selectors, nil
+}
+
+// matchesTags reports whether the context carries all of the tags in selectors.
+func matchesTags(context *api.Context, selectors map[string]string) bool {
+	if len(selectors) == 0 {
+		return true
+	}
+	metadata, err := readContextMetadata(context)
+	if err != nil {
+		return false
+	}
+	for key, value := range selectors {
+		if tag, ok := metadata.Tags[key]; !ok || tag != value {
+			return false
+		}
+	}
+	return true
+}
+
+// formatTags formats tags as "key=value" pairs sorted by key.
+func formatTags(tags map[string]string) string {
+	pairs := make([]string, 0, len(tags))
+	for _, key := range sortedKeys(tags) {
+		pairs = append(pairs, key+"="+tags[key])
+	}
+	return strings.Join(pairs, ",")
+}
+
+// readContextMetadata returns the tags and note of a context.
+func readContextMetadata(context *api.Context) (contextMetadata, error) {
+	var metadata contextMetadata
+	object, ok := context.Extensions[contextMetadataExtension]
+	if !ok || object == nil {
+		return metadata, nil
+	}
+	var raw []byte
+	if unknown, ok := object.(*runtime.Unknown); ok {
+		raw = unknown.Raw
+	} else {
+		var err error
+		if raw, err = json.Marshal(object); err != nil {
+			return metadata, fmt.Errorf("failed to read extension '%s': %w", contextMetadataExtension, err)
+		}
+	}
+	if err := json.Unmarshal(raw, &metadata); err != nil {
+		return metadata, fmt.Errorf("failed to parse extension '%s': %w", contextMetadataExtension, err)
+	}
+	return metadata, nil
+}
+
+// writeContextMetadata stores the tags and note of a context, removing the
+// extension when both are empty.
+func writeContextMetadata(context *api.Context, metadata contextMetadata) error {
+	if len(metadata.Tags) == 0 && metadata.Note == "" {
+		delete(context.Extensions, contextMetadataExtension)
+		return nil
+	}
+	raw, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("failed to serialize extension '%s': %w", contextMetadataExtension, err)
+	}
+	if context.Extensions == nil {
+		context.Extensions = make(map[string]runtime.Object)
+	}
+	context.Extensions[contextMetadataExtension] = &runtime.Unknown{Raw: raw, ContentType: runtime.ContentTypeJSON}
+	return nil
+}
+
+// mergeContextMetadata keeps the tags and note of existing when incoming
+// replaces it: tags are combined, with those of incoming taking precedence,
+// and the note of existing is kept unless incoming has one.
+func mergeContextMetadata(existing, incoming *api.Context) error {
+	previous, err := readContextMetadata(existing)
+	if err != nil || (len(previous.Tags) == 0 && previous.Note == "") {
+		return err
+	}
+	metadata, err := readContextMetadata(incoming)
+	if err != nil {
+		return err
+	}
+	tags := make(map[string]string, len(previous.Tags)+len(metadata.Tags))
+	for key, value := range previous.Tags {
+		tags[key] = value
+	}
+	for key, value := range metadata.Tags {
+		tags[key] = value
+	}
+	metadata.Tags = tags
+	if metadata.Note == "" {
+		metadata.Note = previous.Note
+	}
+	return writeContextMetadata(incoming, metadata)
+}
+
+func init() {
+	rootCmd.AddCommand(tagCmd)
+	rootCmd.AddCommand(noteCmd)
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const tagTestKubeconfig = `
+apiVersion: v1
+clusters:
+- cluster:
+    server: https://c1
+  name: c1
+contexts:
+- context:
+    cluster: c1
+    user: u1
+  name: prod-eu
+- context:
+    cluster: c1
+    user: u1
+  name: prod-us
+- context:
+    cluster: c1
+    user: u1
+  name: dev
+current-context: dev
+kind: Config
+preferences: {}
+users:
+- name: u1
+  user:
+    token: t
+`
+
+func TestTagAndNoteCommands(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "kedit-test-tag-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	kubeconfigPath := filepath.Join(tempDir, "config")
+	assert.NoError(t, ioutil.WriteFile(kubeconfigPath, []byte(tagTestKubeconfig), 0600))
+
+	t.Run("tag contexts matching a pattern", func(t *testing.T) {
+		output := executeCommandC(t, "tag", "context", "prod-*", "env=prod", "team=payments", "--kubeconfig", kubeconfigPath)
+		assert.Equal(t, "Context 'prod-eu' is tagged env=prod,team=payments.\nContext 'prod-us' is tagged env=prod,team=payments.", output)
+
+		// Other tools only see an extension they ignore.
+		config, err := clientcmd.LoadFromFile(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.Contains(t, config.Contexts["prod-eu"].Extensions, contextMetadataExtension)
+		assert.Empty(t, config.Contexts["dev"].Extensions)
+	})
+
+	t.Run("remove a tag", func(t *testing.T) {
+		output := executeCommandC(t, "tag", "context", "prod-us", "team-", "--kubeconfig", kubeconfigPath)
+		assert.Equal(t, "Context 'prod-us' is tagged env=prod.", output)
+	})
+
+	t.Run("set a note", func(t *testing.T) {
+		output := executeCommandC(t, "note", "context", "prod-eu", "Owned by payments", "--kubeconfig", kubeconfigPath)
+		assert.Equal(t, "Set the note of context 'prod-eu'.", output)
+	})
+
+	t.Run("list by tag", func(t *testing.T) {
+		output := executeCommandC(t, "list", "context", "--tag", "env=prod", "--kubeconfig", kubeconfigPath)
+		assert.Equal(t, "Contexts:\n- prod-eu\n- prod-us", output)
+
+		output = executeCommandC(t, "list", "context", "--tag", "env=prod", "--tag", "team=payments", "--kubeconfig", kubeconfigPath)
+		assert.Equal(t, "Contexts:\n- prod-eu", output)
+
+		output = executeCommandC(t, "list", "context", "--tag", "env=staging", "--kubeconfig", kubeconfigPath)
+		assert.Equal(t, "No contexts tagged env=staging found.", output)
+	})
+
+	t.Run("list wide", func(t *testing.T) {
+		output := executeCommandC(t, "list", "context", "-o", "wide", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "NAME     CLUSTER  USER  TAGS                    NOTE")
+		assert.Contains(t, output, "prod-eu  c1       u1    env=prod,team=payments  Owned by payments")
+		assert.Contains(t, output, "prod-us  c1       u1    env=prod")
+	})
+
+	t.Run("tags survive rename", func(t *testing.T) {
+		executeCommandC(t, "rename", "context", "prod-eu", "eu", "--kubeconfig", kubeconfigPath)
+		output := executeCommandC(t, "list", "context", "--tag", "team=payments", "--kubeconfig", kubeconfigPath)
+		assert.Equal(t, "Contexts:\n- eu", output)
+	})
+
+	t.Run("remove a note", func(t *testing.T) {
+		output := executeCommandC(t, "note", "context", "eu", "", "--kubeconfig", kubeconfigPath)
+		assert.Equal(t, "Removed the note of context 'eu'.", output)
+		output = executeCommandC(t, "list", "context", "-o", "wide", "--kubeconfig", kubeconfigPath)
+		assert.NotContains(t, output, "Owned by payments")
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		output := executeCommandC(t, "tag", "cluster", "c1", "env=prod", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Only contexts can be tagged")
+		output = executeCommandC(t, "tag", "context", "eu", "env", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "invalid tag 'env'")
+		output = executeCommandC(t, "tag", "context", "nope-*", "env=prod", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "no contexts in")
+		output = executeCommandC(t, "list", "cluster", "--tag", "env=prod", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "flag --tag only applies to contexts")
+	})
+}
+
+func TestMergeKeepsContextTags(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "kedit-test-tag-merge-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	targetPath := filepath.Join(tempDir, "config")
+	assert.NoError(t, ioutil.WriteFile(targetPath, []byte(tagTestKubeconfig), 0600))
+	sourcePath := filepath.Join(tempDir, "source")
+	assert.NoError(t, ioutil.WriteFile(sourcePath, []byte(tagTestKubeconfig), 0600))
+
+	executeCommandC(t, "tag", "context", "prod-eu", "env=prod", "team=payments", "--kubeconfig", targetPath)
+	executeCommandC(t, "note", "context", "prod-eu", "Owned by payments", "--kubeconfig", targetPath)
+	executeCommandC(t, "tag", "context", "prod-eu", "region=eu", "team=core", "--kubeconfig", sourcePath)
+	executeCommandC(t, "tag", "context", "dev", "env=dev", "--kubeconfig", sourcePath)
+
+	t.Run("tags of the source context are merged in", func(t *testing.T) {
+		executeCommandC(t, "merge", "dev", "--from", sourcePath, "--name", "dev2", "--kubeconfig", targetPath)
+		output := executeCommandC(t, "list", "context", "--tag", "env=dev", "--kubeconfig", targetPath)
+		assert.Equal(t, "Contexts:\n- dev2", output)
+	})
+
+	t.Run("tags and note of an overwritten context are kept", func(t *testing.T) {
+		executeCommandC(t, "merge", "prod-eu", "--from", sourcePath, "--kubeconfig", targetPath)
+		config, err := loadKubeconfig(targetPath)
+		assert.NoError(t, err)
+		metadata, err := readContextMetadata(config.Contexts["prod-eu"])
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"env": "prod", "team": "core", "region": "eu"}, metadata.Tags)
+		assert.Equal(t, "Owned by payments", metadata.Note)
+	})
+}
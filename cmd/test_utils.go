@@ -8,6 +8,8 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -26,6 +28,7 @@ func executeCommandC(t *testing.T, args ...string) string {
 	os.Stderr = wErr
 
 	// Reset command for a clean run
+	resetFlags(rootCmd)
 	rootCmd.SetArgs(nil)
 	// Set the arguments for the root command.
 	rootCmd.SetArgs(args)
@@ -50,3 +53,43 @@ func executeCommandC(t *testing.T, args ...string) string {
 	// Return the captured output, combining stdout and stderr, and trimming any extra space
 	return strings.TrimSpace(bufOut.String() + bufErr.String())
 }
+
+// executeCommandWithInput runs a command like executeCommandC, feeding input to its stdin.
+func executeCommandWithInput(t *testing.T, input string, args ...string) string {
+	t.Helper()
+
+	oldStdin := os.Stdin
+	rIn, wIn, err := os.Pipe()
+	assert.NoError(t, err)
+	_, err = wIn.WriteString(input)
+	assert.NoError(t, err)
+	wIn.Close()
+	os.Stdin = rIn
+	defer func() {
+		os.Stdin = oldStdin
+		rIn.Close()
+	}()
+
+	return executeCommandC(t, args...)
+}
+
+// resetFlags restores every flag of cmd and its subcommands to its default value,
+// so that flags set by one test do not leak into the next.
+func resetFlags(cmd *cobra.Command) {
+	reset := func(f *pflag.Flag) {
+		if !f.Changed {
+			return
+		}
+		if sliceValue, ok := f.Value.(pflag.SliceValue); ok {
+			_ = sliceValue.Replace(nil)
+		} else {
+			_ = f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	for _, subCmd := range cmd.Commands() {
+		resetFlags(subCmd)
+	}
+}
@@ -0,0 +1,607 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// ANSI escape sequences used by the terminal UI.
+const (
+	ansiReset           = "\x1b[0m"
+	ansiBold            = "\x1b[1m"
+	ansiReverse         = "\x1b[7m"
+	ansiClearScreen     = "\x1b[H\x1b[2J"
+	ansiAltScreenOn     = "\x1b[?1049h\x1b[?25l"
+	ansiAltScreenOff    = "\x1b[?25h\x1b[?1049l"
+	tuiHelp             = "tab/left/right pane  up/down/j/k move  enter/u use  r rename  d delete  p prune  i details  q quit"
+	tuiDefaultWidth     = 80
+	tuiDefaultHeight    = 24
+	tuiReservedRows     = 6 // title, pane headers, info, message, prompt and help lines
+	tuiPaneSeparator    = " | "
+	tuiMinimumPaneWidth = 8
+)
+
+// tuiPanes lists the item types shown by the terminal UI, in pane order.
+var tuiPanes = []string{"context", "cluster", "user"}
+
+// tuiMode is the input mode of the terminal UI.
+type tuiMode int
+
+const (
+	tuiModeNormal tuiMode = iota
+	tuiModeRename
+	tuiModeConfirmDelete
+	tuiModeConfirmPrune
+	tuiModeDetails
+)
+
+// tuiCmd represents the tui command
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Browse and edit the kubeconfig in a full-screen terminal UI",
+	Long: `Open a full-screen terminal UI with panes for contexts, clusters and users.
+
+Items related to the selected one are highlighted in the other panes, the
+current context is marked with '*', and contexts pointing to missing clusters
+or users are marked as dangling.
+
+Keys:
+  tab, left, right  Switch pane
+  up, down, j, k    Move the selection
+  enter, u          Switch current-context to the selected context
+  r                 Rename the selected item (contexts are updated)
+  d                 Delete the selected item, warning about contexts that use it
+  p                 Prune unreferenced clusters and users
+  i                 Show the redacted details of the selected item
+  q, ctrl-c         Quit
+
+Every change is saved immediately, exactly as the rename, delete and prune
+commands would save it.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+			return fmt.Errorf("kedit tui requires an interactive terminal")
+		}
+
+		config, err := loadKubeconfig(resolvedKubeconfigPath)
+		if err != nil {
+			return fmt.Errorf("error loading kubeconfig from '%s': %w", resolvedKubeconfigPath, err)
+		}
+		return runTUI(newTUIModel(config, resolvedKubeconfigPath), os.Stdin, os.Stdout)
+	},
+}
+
+// runTUI puts the terminal in raw mode and runs the UI until the user quits.
+func runTUI(model *tuiModel, in *os.File, out *os.File) error {
+	oldState, err := term.MakeRaw(int(in.Fd()))
+	if err != nil {
+		return fmt.Errorf("failed to put terminal in raw mode: %w", err)
+	}
+	defer term.Restore(int(in.Fd()), oldState)
+
+	fmt.Fprint(out, ansiAltScreenOn)
+	defer fmt.Fprint(out, ansiAltScreenOff)
+
+	buf := make([]byte, 256)
+	for {
+		width, height, err := term.GetSize(int(out.Fd()))
+		if err != nil {
+			width, height = tuiDefaultWidth, tuiDefaultHeight
+		}
+		// Raw mode disables the translation of "\n" to "\r\n".
+		fmt.Fprint(out, ansiClearScreen+strings.ReplaceAll(model.render(width, height), "\n", "\r\n"))
+
+		n, err := in.Read(buf)
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("failed to read from terminal: %w", err)
+		}
+		for _, key := range parseKeys(buf[:n]) {
+			if model.handleKey(key) {
+				return nil
+			}
+		}
+	}
+}
+
+// tuiModel holds the state of the terminal UI. It is independent of the
+// terminal so it can be driven by key names in tests.
+type tuiModel struct {
+	config  *api.Config
+	path    string
+	pane    int     // index into tuiPanes
+	cursors [3]int  // selected row per pane
+	mode    tuiMode // current input mode
+	input   string  // text typed in rename mode
+	pending []pruneCandidate
+	details []string // lines shown in details mode
+	message string   // result of the last action
+}
+
+// newTUIModel returns a model for the given config, which is saved to path.
+func newTUIModel(config *api.Config, path string) *tuiModel {
+	return &tuiModel{config: config, path: path}
+}
+
+// items returns the sorted names shown in a pane.
+func (m *tuiModel) items(pane int) []string {
+	switch tuiPanes[pane] {
+	case "context":
+		return sortedKeys(m.config.Contexts)
+	case "cluster":
+		return sortedKeys(m.config.Clusters)
+	default:
+		return sortedKeys(m.config.AuthInfos)
+	}
+}
+
+// selected returns the type and name of the selected item in the active pane.
+func (m *tuiModel) selected() (string, string, bool) {
+	items := m.items(m.pane)
+	if len(items) == 0 {
+		return tuiPanes[m.pane], "", false
+	}
+	return tuiPanes[m.pane], items[m.cursors[m.pane]], true
+}
+
+// clampCursors keeps every cursor within the items of its pane.
+func (m *tuiModel) clampCursors() {
+	for pane := range tuiPanes {
+		n := len(m.items(pane))
+		if m.cursors[pane] >= n {
+			m.cursors[pane] = n - 1
+		}
+		if m.cursors[pane] < 0 {
+			m.cursors[pane] = 0
+		}
+	}
+}
+
+// selectItem moves the cursor of a pane to the named item, if it exists.
+func (m *tuiModel) selectItem(pane int, name string) {
+	for i, item := range m.items(pane) {
+		if item == name {
+			m.cursors[pane] = i
+			return
+		}
+	}
+}
+
+// related reports whether the item of the given type is related to the
+// selected item of the active pane through a context.
+func (m *tuiModel) related(itemType, name string) bool {
+	selectedType, selectedName, ok := m.selected()
+	if !ok || selectedType == itemType {
+		return false
+	}
+	if selectedType == "context" {
+		context := m.config.Contexts[selectedName]
+		return (itemType == "cluster" && context.Cluster == name) || (itemType == "user" && context.AuthInfo == name)
+	}
+	for _, contextName := range contextsReferencing(m.config, selectedType, selectedName) {
+		context := m.config.Contexts[contextName]
+		if (itemType == "context" && contextName == name) ||
+			(itemType == "cluster" && context.Cluster == name) ||
+			(itemType == "user" && context.AuthInfo == name) {
+			return true
+		}
+	}
+	return false
+}
+
+// handleKey applies a key press and reports whether the UI should quit.
+func (m *tuiModel) handleKey(key string) bool {
+	if key == "ctrl-c" {
+		return true
+	}
+	switch m.mode {
+	case tuiModeRename:
+		m.handleRenameKey(key)
+	case tuiModeConfirmDelete, tuiModeConfirmPrune:
+		m.handleConfirmKey(key)
+	case tuiModeDetails:
+		if key == "esc" || key == "q" || key == "i" || key == "enter" {
+			m.mode = tuiModeNormal
+		}
+	default:
+		return m.handleNormalKey(key)
+	}
+	return false
+}
+
+// handleNormalKey handles navigation and starts actions.
+func (m *tuiModel) handleNormalKey(key string) bool {
+	m.message = ""
+	switch key {
+	case "q":
+		return true
+	case "tab", "right", "l":
+		m.pane = (m.pane + 1) % len(tuiPanes)
+	case "left", "h":
+		m.pane = (m.pane + len(tuiPanes) - 1) % len(tuiPanes)
+	case "down", "j":
+		if m.cursors[m.pane] < len(m.items(m.pane))-1 {
+			m.cursors[m.pane]++
+		}
+	case "up", "k":
+		if m.cursors[m.pane] > 0 {
+			m.cursors[m.pane]--
+		}
+	case "enter", "u":
+		m.useContext()
+	case "r":
+		if _, name, ok := m.selected(); ok {
+			m.mode = tuiModeRename
+			m.input = name
+		}
+	case "d":
+		if _, _, ok := m.selected(); ok {
+			m.mode = tuiModeConfirmDelete
+		}
+	case "p":
+		m.pending = unreferencedEntryCandidates(m.config)
+		if len(m.pending) == 0 {
+			m.message = "No unreferenced clusters or users found. Nothing to prune."
+			return false
+		}
+		m.mode = tuiModeConfirmPrune
+	case "i":
+		m.showDetails()
+	}
+	return false
+}
+
+// handleRenameKey edits the new name and performs the rename on enter.
+func (m *tuiModel) handleRenameKey(key string) {
+	switch key {
+	case "esc":
+		m.mode = tuiModeNormal
+	case "backspace":
+		if m.input != "" {
+			_, size := utf8.DecodeLastRuneInString(m.input)
+			m.input = m.input[:len(m.input)-size]
+		}
+	case "enter":
+		m.mode = tuiModeNormal
+		itemType, oldName, _ := m.selected()
+		newName := m.input
+		if newName == "" || newName == oldName {
+			m.message = "Rename cancelled."
+			return
+		}
+		messages, err := renameItem(m.config, itemType, oldName, newName)
+		if err != nil {
+			m.message = "Error: " + err.Error()
+			return
+		}
+		if m.save() {
+			m.selectItem(m.pane, newName)
+			m.message = strings.Join(messages, " ")
+		}
+	default:
+		if utf8.RuneCountInString(key) == 1 {
+			m.input += key
+		}
+	}
+}
+
+// handleConfirmKey performs the pending delete or prune when confirmed with 'y'.
+func (m *tuiModel) handleConfirmKey(key string) {
+	mode := m.mode
+	m.mode = tuiModeNormal
+	if key != "y" && key != "Y" {
+		m.message = "No changes made."
+		return
+	}
+
+	if mode == tuiModeConfirmPrune {
+		applyPruneCandidates(m.config, m.pending)
+		if m.save() {
+			clusters, users := countPruneCandidates(m.pending)
+			m.message = fmt.Sprintf("Pruned %d cluster(s) and %d user(s).", clusters, users)
+		}
+		m.pending = nil
+		return
+	}
+
+	itemType, name, _ := m.selected()
+	if _, err := deleteItem(m.config, itemType, name); err != nil {
+		m.message = "Error: " + err.Error()
+		return
+	}
+	if m.save() {
+		m.message = fmt.Sprintf("Deleted %s '%s'.", itemType, name)
+	}
+}
+
+// useContext makes the selected context the current-context.
+func (m *tuiModel) useContext() {
+	itemType, name, ok := m.selected()
+	if !ok || itemType != "context" {
+		m.message = "Select a context to switch to it."
+		return
+	}
+	if m.config.CurrentContext == name {
+		m.message = fmt.Sprintf("'%s' is already the current-context.", name)
+		return
+	}
+	m.config.CurrentContext = name
+	if m.save() {
+		m.message = fmt.Sprintf("Switched current-context to '%s'.", name)
+	}
+}
+
+// showDetails switches to details mode for the selected item, with secrets redacted.
+func (m *tuiModel) showDetails() {
+	itemType, name, ok := m.selected()
+	if !ok {
+		return
+	}
+	item := api.NewConfig()
+	switch itemType {
+	case "context":
+		item.Contexts[name] = m.config.Contexts[name]
+	case "cluster":
+		item.Clusters[name] = m.config.Clusters[name]
+	case "user":
+		item.AuthInfos[name] = m.config.AuthInfos[name]
+	}
+	redacted, err := redactConfig(item)
+	if err != nil {
+		m.message = "Error: " + err.Error()
+		return
+	}
+	content, err := clientcmd.Write(*redacted)
+	if err != nil {
+		m.message = "Error: " + err.Error()
+		return
+	}
+
+	m.details = []string{fmt.Sprintf("Details of %s '%s' (secrets redacted):", itemType, name), ""}
+	m.details = append(m.details, strings.Split(strings.TrimRight(string(content), "\n"), "\n")...)
+	if itemType != "context" {
+		references := contextsReferencing(m.config, itemType, name)
+		m.details = append(m.details, "", fmt.Sprintf("Referenced by %d context(s): %s", len(references), strings.Join(references, ", ")))
+	}
+	m.mode = tuiModeDetails
+}
+
+// save writes the config back to its file. On failure the config is reloaded
+// from disk, so the UI never shows changes that were not saved.
+func (m *tuiModel) save() bool {
+	err := saveKubeconfig(m.config, m.path)
+	if err == nil {
+		m.clampCursors()
+		return true
+	}
+	m.message = "Error: " + err.Error()
+	if config, loadErr := loadKubeconfig(m.path); loadErr == nil {
+		m.config = config
+	}
+	m.clampCursors()
+	return false
+}
+
+// deleteWarning describes the contexts that would be left dangling by deleting
+// the selected cluster or user.
+func (m *tuiModel) deleteWarning() string {
+	itemType, name, _ := m.selected()
+	if itemType == "context" {
+		if m.config.CurrentContext == name {
+			return fmt.Sprintf("Warning: '%s' is the current-context, which will be cleared.", name)
+		}
+		return ""
+	}
+	references := contextsReferencing(m.config, itemType, name)
+	if len(references) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Warning: %d context(s) reference %s '%s' and will be left dangling: %s.", len(references), itemType, name, strings.Join(references, ", "))
+}
+
+// describeSelection returns a one-line description of the selected item and its references.
+func (m *tuiModel) describeSelection() string {
+	itemType, name, ok := m.selected()
+	if !ok {
+		return fmt.Sprintf("No %ss.", itemType)
+	}
+	if itemType == "context" {
+		context := m.config.Contexts[name]
+		description := fmt.Sprintf("context '%s': cluster '%s', user '%s'", name, context.Cluster, context.AuthInfo)
+		if context.Namespace != "" {
+			description += fmt.Sprintf(", namespace '%s'", context.Namespace)
+		}
+		if missing := missingContextReferences(m.config, context); len(missing) > 0 {
+			description += " (missing " + strings.Join(missing, ", ") + ")"
+		}
+		return description
+	}
+	references := contextsReferencing(m.config, itemType, name)
+	if len(references) == 0 {
+		return fmt.Sprintf("%s '%s': not referenced by any context", itemType, name)
+	}
+	return fmt.Sprintf("%s '%s': used by %s", itemType, name, strings.Join(references, ", "))
+}
+
+// cellLabel returns the text of an item in a pane, without styling.
+func (m *tuiModel) cellLabel(pane int, name string) string {
+	marker := " "
+	suffix := ""
+	switch tuiPanes[pane] {
+	case "context":
+		if m.config.CurrentContext == name {
+			marker = "*"
+		}
+		if len(missingContextReferences(m.config, m.config.Contexts[name])) > 0 {
+			suffix = " (dangling)"
+		}
+	default:
+		if len(contextsReferencing(m.config, tuiPanes[pane], name)) == 0 {
+			suffix = " (unused)"
+		}
+	}
+	return marker + " " + name + suffix
+}
+
+// render draws the UI for a terminal of the given size.
+func (m *tuiModel) render(width, height int) string {
+	var b strings.Builder
+	fmt.Fprintln(&b, fitText("kedit tui - "+m.path, width))
+
+	if m.mode == tuiModeDetails {
+		for i, line := range m.details {
+			if i >= height-2 {
+				break
+			}
+			fmt.Fprintln(&b, fitText(line, width))
+		}
+		fmt.Fprint(&b, fitText("Press esc, q or i to return.", width))
+		return b.String()
+	}
+
+	paneWidth := (width - len(tuiPaneSeparator)*(len(tuiPanes)-1)) / len(tuiPanes)
+	if paneWidth < tuiMinimumPaneWidth {
+		paneWidth = tuiMinimumPaneWidth
+	}
+	rows := height - tuiReservedRows
+	if rows < 1 {
+		rows = 1
+	}
+
+	headers := make([]string, len(tuiPanes))
+	for pane, itemType := range tuiPanes {
+		header := padText(fmt.Sprintf("%sS (%d)", strings.ToUpper(itemType), len(m.items(pane))), paneWidth)
+		if pane == m.pane {
+			header = ansiBold + header + ansiReset
+		}
+		headers[pane] = header
+	}
+	fmt.Fprintln(&b, strings.Join(headers, tuiPaneSeparator))
+
+	for row := 0; row < rows; row++ {
+		cells := make([]string, len(tuiPanes))
+		for pane, itemType := range tuiPanes {
+			items := m.items(pane)
+			offset := 0
+			if m.cursors[pane] >= rows {
+				offset = m.cursors[pane] - rows + 1
+			}
+			index := offset + row
+			if index >= len(items) {
+				cells[pane] = strings.Repeat(" ", paneWidth)
+				continue
+			}
+			cell := padText(m.cellLabel(pane, items[index]), paneWidth)
+			switch {
+			case pane == m.pane && index == m.cursors[pane]:
+				cell = ansiReverse + cell + ansiReset
+			case m.related(itemType, items[index]):
+				cell = ansiBold + cell + ansiReset
+			}
+			cells[pane] = cell
+		}
+		fmt.Fprintln(&b, strings.Join(cells, tuiPaneSeparator))
+	}
+
+	fmt.Fprintln(&b, fitText(m.describeSelection(), width))
+	fmt.Fprintln(&b, fitText(m.message, width))
+	itemType, name, _ := m.selected()
+	switch m.mode {
+	case tuiModeRename:
+		fmt.Fprint(&b, fitText(fmt.Sprintf("Rename %s '%s' to: %s_", itemType, name, m.input), width))
+	case tuiModeConfirmDelete:
+		prompt := fmt.Sprintf("Delete %s '%s'? [y/N]", itemType, name)
+		if warning := m.deleteWarning(); warning != "" {
+			prompt = warning + " " + prompt
+		}
+		fmt.Fprint(&b, fitText(prompt, width))
+	case tuiModeConfirmPrune:
+		names := make([]string, len(m.pending))
+		for i, candidate := range m.pending {
+			names[i] = fmt.Sprintf("%s '%s'", candidate.kind, candidate.name)
+		}
+		fmt.Fprint(&b, fitText(fmt.Sprintf("Prune %s? [y/N]", strings.Join(names, ", ")), width))
+	default:
+		fmt.Fprint(&b, fitText(tuiHelp, width))
+	}
+	return b.String()
+}
+
+// fitText truncates text to at most width runes.
+func fitText(text string, width int) string {
+	if utf8.RuneCountInString(text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	if width <= 1 {
+		return string(runes[:width])
+	}
+	return string(runes[:width-1]) + "~"
+}
+
+// padText truncates or pads text with spaces to exactly width runes.
+func padText(text string, width int) string {
+	text = fitText(text, width)
+	return text + strings.Repeat(" ", width-utf8.RuneCountInString(text))
+}
+
+// parseKeys translates raw terminal input into key names such as "up",
+// "enter" or "ctrl-c". Printable characters are returned as themselves.
+func parseKeys(input []byte) []string {
+	var keys []string
+	for len(input) > 0 {
+		if input[0] == 0x1b {
+			if len(input) >= 3 && (input[1] == '[' || input[1] == 'O') {
+				switch input[2] {
+				case 'A':
+					keys = append(keys, "up")
+				case 'B':
+					keys = append(keys, "down")
+				case 'C':
+					keys = append(keys, "right")
+				case 'D':
+					keys = append(keys, "left")
+				}
+				input = input[3:]
+				continue
+			}
+			keys = append(keys, "esc")
+			input = input[1:]
+			continue
+		}
+
+		switch input[0] {
+		case 0x03:
+			keys = append(keys, "ctrl-c")
+		case '\t':
+			keys = append(keys, "tab")
+		case '\r', '\n':
+			keys = append(keys, "enter")
+		case 0x7f, 0x08:
+			keys = append(keys, "backspace")
+		default:
+			r, size := utf8.DecodeRune(input)
+			if r != utf8.RuneError && r >= ' ' {
+				keys = append(keys, string(r))
+			}
+			input = input[size:]
+			continue
+		}
+		input = input[1:]
+	}
+	return keys
+}
+
+func init() {
+	rootCmd.AddCommand(tuiCmd)
+}
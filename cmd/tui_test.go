@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func TestTUIModel(t *testing.T) {
+	// Helper to create a model for a kubeconfig with one unreferenced cluster and user.
+	createModelForTUI := func(tempDir string) (*tuiModel, string) {
+		kubeconfigPath := filepath.Join(tempDir, "config")
+		err := ioutil.WriteFile(kubeconfigPath, []byte(`
+apiVersion: v1
+clusters:
+- cluster:
+    server: https://cluster1
+  name: cluster1
+- cluster:
+    server: https://cluster2
+  name: cluster2
+- cluster:
+    server: https://orphan
+  name: orphan-cluster
+contexts:
+- context:
+    cluster: cluster1
+    user: user1
+  name: context1
+- context:
+    cluster: cluster2
+    user: user2
+  name: context2
+current-context: context1
+kind: Config
+preferences: {}
+users:
+- name: orphan-user
+  user:
+    token: orphan-token
+- name: user1
+  user:
+    token: secret-token-1
+- name: user2
+  user:
+    password: secret-password
+    username: admin
+`), 0644)
+		assert.NoError(t, err)
+		config, err := loadKubeconfig(kubeconfigPath)
+		assert.NoError(t, err)
+		return newTUIModel(config, kubeconfigPath), kubeconfigPath
+	}
+
+	// pressKeys feeds each key to the model and reports whether it asked to quit.
+	pressKeys := func(m *tuiModel, keys ...string) bool {
+		for _, key := range keys {
+			if m.handleKey(key) {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Test switching the current-context.
+	t.Run("tui use context", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-tui-use-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		m, kubeconfigPath := createModelForTUI(tempDir)
+
+		assert.False(t, pressKeys(m, "down", "enter"))
+		assert.Equal(t, "Switched current-context to 'context2'.", m.message)
+
+		config, err := clientcmd.LoadFromFile(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.Equal(t, "context2", config.CurrentContext)
+	})
+
+	// Test renaming a cluster, which updates the contexts referencing it.
+	t.Run("tui rename cluster", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-tui-rename-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		m, kubeconfigPath := createModelForTUI(tempDir)
+
+		pressKeys(m, "tab", "r", "backspace", "backspace", "backspace", "backspace", "backspace", "backspace", "backspace", "backspace", "p", "r", "o", "d", "enter")
+		assert.Equal(t, "Renamed cluster 'cluster1' to 'prod'. Updated 1 context(s) to reference the new cluster name 'prod'.", m.message)
+
+		config, err := clientcmd.LoadFromFile(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.Contains(t, config.Clusters, "prod")
+		assert.NotContains(t, config.Clusters, "cluster1")
+		assert.Equal(t, "prod", config.Contexts["context1"].Cluster)
+		// The renamed cluster stays selected.
+		_, name, _ := m.selected()
+		assert.Equal(t, "prod", name)
+	})
+
+	// Test deleting a cluster in use, which warns about the dangling context first.
+	t.Run("tui delete with warning", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-tui-delete-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		m, kubeconfigPath := createModelForTUI(tempDir)
+
+		pressKeys(m, "tab", "d")
+		assert.Contains(t, m.render(200, 24), "Warning: 1 context(s) reference cluster 'cluster1' and will be left dangling: context1. Delete cluster 'cluster1'? [y/N]")
+
+		pressKeys(m, "n")
+		assert.Equal(t, "No changes made.", m.message)
+
+		pressKeys(m, "d", "y")
+		assert.Equal(t, "Deleted cluster 'cluster1'.", m.message)
+		assert.Contains(t, m.render(200, 24), "context1 (dangling)")
+
+		config, err := clientcmd.LoadFromFile(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.NotContains(t, config.Clusters, "cluster1")
+	})
+
+	// Test pruning unreferenced clusters and users.
+	t.Run("tui prune", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-tui-prune-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		m, kubeconfigPath := createModelForTUI(tempDir)
+
+		pressKeys(m, "p")
+		assert.Contains(t, m.render(200, 24), "Prune cluster 'orphan-cluster', user 'orphan-user'? [y/N]")
+		pressKeys(m, "y")
+		assert.Equal(t, "Pruned 1 cluster(s) and 1 user(s).", m.message)
+
+		config, err := clientcmd.LoadFromFile(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.Len(t, config.Clusters, 2)
+		assert.Len(t, config.AuthInfos, 2)
+
+		pressKeys(m, "p")
+		assert.Equal(t, "No unreferenced clusters or users found. Nothing to prune.", m.message)
+	})
+
+	// Test that details are redacted and that quitting works.
+	t.Run("tui details and quit", func(t *testing.T) {
+		tempDir, err := ioutil.TempDir("", "kedit-test-tui-details-")
+		assert.NoError(t, err)
+		defer os.RemoveAll(tempDir)
+		m, _ := createModelForTUI(tempDir)
+
+		pressKeys(m, "left", "down", "down", "i")
+		screen := m.render(200, 40)
+		assert.Contains(t, screen, "Details of user 'user2' (secrets redacted):")
+		assert.Contains(t, screen, "password: REDACTED")
+		assert.NotContains(t, screen, "secret-password")
+		assert.Contains(t, screen, "Referenced by 1 context(s): context2")
+
+		assert.False(t, pressKeys(m, "esc"))
+		assert.True(t, pressKeys(m, "q"))
+	})
+}
+
+func TestParseKeys(t *testing.T) {
+	assert.Equal(t, []string{"up", "down", "right", "left", "esc"}, parseKeys([]byte("\x1b[A\x1b[B\x1bOC\x1b[D\x1b")))
+	assert.Equal(t, []string{"a", "é", "tab", "enter", "backspace", "ctrl-c"}, parseKeys([]byte("aé\t\r\x7f\x03")))
+}
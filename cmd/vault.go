@@ -0,0 +1,427 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+const (
+	// vaultPassphraseEnv is the environment variable holding the vault passphrase.
+	vaultPassphraseEnv = "KEDIT_VAULT_PASSPHRASE"
+	// vaultPurpose is authenticated along with the vault content.
+	vaultPurpose = "kedit credential vault"
+	// execCredentialAPIVersion is the ExecCredential version kedit emits.
+	execCredentialAPIVersion = "client.authentication.k8s.io/v1"
+)
+
+var (
+	vaultPath    string // Flag for the path of the vault file
+	vaultCommand string // Flag for the command kubectl runs to get sealed credentials
+)
+
+// vaultEntry holds the secrets moved out of one user.
+type vaultEntry struct {
+	Token                 string `json:"token,omitempty"`
+	ClientCertificateData []byte `json:"clientCertificateData,omitempty"`
+	ClientKeyData         []byte `json:"clientKeyData,omitempty"`
+}
+
+// credentialVault is the decrypted content of the vault file.
+type credentialVault struct {
+	Entries map[string]vaultEntry `json:"entries"`
+}
+
+// vaultCmd represents the vault command
+var vaultCmd = &cobra.Command{
+	Use:   "vault",
+	Short: "Move user credentials into an encrypted vault",
+	Long: `Move the tokens and client keys of users out of the kubeconfig into a
+passphrase-encrypted vault file, and back.
+
+A sealed user gets them from 'kedit credential' instead, which kubectl runs as
+an exec credential plugin. The passphrase is read from $KEDIT_VAULT_PASSPHRASE
+or asked for on the terminal.
+
+The vault is stored in the kedit config directory; use --vault to choose
+another file.`,
+}
+
+// vaultSealCmd represents the vault seal command
+var vaultSealCmd = &cobra.Command{
+	Use:   "seal <user_name>...",
+	Short: "Move the secrets of users into the vault",
+	Long: `Move the token, client certificate and client key of each user into the vault
+and replace them with an exec plugin that runs 'kedit credential <entry_name>'.
+The entry name is the user name followed by a hash of the kubeconfig path, so
+that users of the same name in different kubeconfigs can share a vault.
+
+Only embedded secrets can be sealed. A basic auth password cannot be provided
+by an exec plugin and is left in place. Users that already use an exec plugin
+or auth provider cannot be sealed.
+
+The exec plugin runs 'kedit' as found on kubectl's PATH, so that it keeps
+working after kedit is upgraded or moved. --command writes another command,
+such as an absolute path, instead.`,
+	Args: cobra.MinimumNArgs(1), // Requires at least one user_name
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return completeTargetNames("user", toComplete, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadKubeconfig(resolvedKubeconfigPath)
+		if err != nil {
+			return fmt.Errorf("error loading kubeconfig from '%s': %w", resolvedKubeconfigPath, err)
+		}
+		path, err := resolveVaultPath()
+		if err != nil {
+			return err
+		}
+		_, statErr := os.Stat(path)
+		passphrase, err := readPassphrase(vaultPassphraseEnv, "Vault passphrase", os.IsNotExist(statErr))
+		if err != nil {
+			return err
+		}
+		vault, err := loadVault(path, passphrase)
+		if err != nil {
+			return err
+		}
+
+		keys := make(map[string]string)
+		for _, name := range args {
+			authInfo, ok := config.AuthInfos[name]
+			if !ok {
+				return fmt.Errorf("user '%s' not found in '%s'", name, resolvedKubeconfigPath)
+			}
+			if err := checkSealable(name, authInfo); err != nil {
+				return err
+			}
+			// The user is not sealed, so an entry of the same name is left over
+			// from an interrupted seal or unseal and is replaced.
+			if keys[name], err = vaultEntryName(resolvedKubeconfigPath, name); err != nil {
+				return err
+			}
+		}
+
+		for _, name := range args {
+			authInfo := config.AuthInfos[name]
+			vault.Entries[keys[name]] = vaultEntry{
+				Token:                 authInfo.Token,
+				ClientCertificateData: authInfo.ClientCertificateData,
+				ClientKeyData:         authInfo.ClientKeyData,
+			}
+			authInfo.Token = ""
+			authInfo.ClientCertificateData = nil
+			authInfo.ClientKeyData = nil
+			authInfo.Exec = vaultExecConfig(keys[name])
+		}
+
+		// Save the vault first, so that the secrets are never lost, and restore
+		// it if the kubeconfig cannot be saved, so that it holds no entries the
+		// kubeconfig does not reference.
+		previous, readErr := os.ReadFile(path)
+		if err := saveVault(path, vault, passphrase); err != nil {
+			return err
+		}
+		if err := saveKubeconfig(config, resolvedKubeconfigPath); err != nil {
+			if restoreErr := restoreVault(path, previous, readErr == nil); restoreErr != nil {
+				return fmt.Errorf("error saving kubeconfig to '%s' after sealing: %w (%v)", resolvedKubeconfigPath, err, restoreErr)
+			}
+			return fmt.Errorf("error saving kubeconfig to '%s' after sealing: %w", resolvedKubeconfigPath, err)
+		}
+		for _, name := range args {
+			fmt.Printf("Sealed the credentials of user '%s' into '%s'.\n", name, path)
+			if config.AuthInfos[name].Password != "" {
+				fmt.Printf("Warning: the password of user '%s' cannot be provided by an exec plugin and was left in place.\n", name)
+			}
+		}
+		return nil
+	},
+}
+
+// vaultUnsealCmd represents the vault unseal command
+var vaultUnsealCmd = &cobra.Command{
+	Use:   "unseal <user_name>...",
+	Short: "Move the secrets of users back from the vault",
+	Long: `Restore the token, client certificate and client key of each sealed user
+from the vault into the kubeconfig, remove the 'kedit credential' exec plugin
+and delete the secrets from the vault.`,
+	Args: cobra.MinimumNArgs(1), // Requires at least one user_name
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return completeTargetNames("user", toComplete, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadKubeconfig(resolvedKubeconfigPath)
+		if err != nil {
+			return fmt.Errorf("error loading kubeconfig from '%s': %w", resolvedKubeconfigPath, err)
+		}
+
+		// Sealed users record the vault they were sealed into.
+		paths := make(map[string]string)
+		keys := make(map[string]string)
+		for _, name := range args {
+			authInfo, ok := config.AuthInfos[name]
+			if !ok {
+				return fmt.Errorf("user '%s' not found in '%s'", name, resolvedKubeconfigPath)
+			}
+			key, path, ok := vaultReference(authInfo.Exec)
+			if !ok {
+				return fmt.Errorf("user '%s' is not sealed", name)
+			}
+			if path == "" {
+				if path, err = resolveVaultPath(); err != nil {
+					return err
+				}
+			}
+			keys[name], paths[name] = key, path
+		}
+
+		passphrase, err := readPassphrase(vaultPassphraseEnv, "Vault passphrase", false)
+		if err != nil {
+			return err
+		}
+		vaults := make(map[string]*credentialVault)
+		for _, name := range args {
+			vault, ok := vaults[paths[name]]
+			if !ok {
+				if vault, err = loadVault(paths[name], passphrase); err != nil {
+					return err
+				}
+				vaults[paths[name]] = vault
+			}
+			entry, ok := vault.Entries[keys[name]]
+			if !ok {
+				return fmt.Errorf("the vault '%s' contains no credentials named '%s'", paths[name], keys[name])
+			}
+			authInfo := config.AuthInfos[name]
+			authInfo.Exec = nil
+			authInfo.Token = entry.Token
+			authInfo.ClientCertificateData = entry.ClientCertificateData
+			authInfo.ClientKeyData = entry.ClientKeyData
+			delete(vault.Entries, keys[name])
+		}
+
+		// Save the kubeconfig first, so that the secrets are never lost.
+		if err := saveKubeconfig(config, resolvedKubeconfigPath); err != nil {
+			return fmt.Errorf("error saving kubeconfig to '%s' after unsealing: %w", resolvedKubeconfigPath, err)
+		}
+		for path, vault := range vaults {
+			if err := saveVault(path, vault, passphrase); err != nil {
+				return err
+			}
+		}
+		for _, name := range args {
+			fmt.Printf("Unsealed the credentials of user '%s' from '%s'.\n", name, paths[name])
+		}
+		return nil
+	},
+}
+
+// credentialCmd represents the credential command
+var credentialCmd = &cobra.Command{
+	Use:   "credential <name>",
+	Short: "Print credentials from the vault as an ExecCredential",
+	Long: `Print the credentials stored in the vault under the given name as a
+client.authentication.k8s.io ExecCredential.
+
+This command is run by kubectl for users sealed with 'kedit vault seal'. The
+passphrase is read from $KEDIT_VAULT_PASSPHRASE or asked for on the terminal.`,
+	Args: cobra.ExactArgs(1), // Requires the name
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := resolveVaultPath()
+		if err != nil {
+			return err
+		}
+		passphrase, err := readPassphrase(vaultPassphraseEnv, "Vault passphrase", false)
+		if err != nil {
+			return err
+		}
+		vault, err := loadVault(path, passphrase)
+		if err != nil {
+			return err
+		}
+		entry, ok := vault.Entries[args[0]]
+		if !ok {
+			return fmt.Errorf("the vault '%s' contains no credentials named '%s'", path, args[0])
+		}
+
+		credential := execCredential{APIVersion: execCredentialAPIVersion, Kind: "ExecCredential", Status: &execCredentialStatus{
+			Token:                 entry.Token,
+			ClientCertificateData: string(entry.ClientCertificateData),
+			ClientKeyData:         string(entry.ClientKeyData),
+		}}
+		content, err := json.Marshal(credential)
+		if err != nil {
+			return fmt.Errorf("failed to serialize credential: %w", err)
+		}
+		fmt.Println(string(content))
+		return nil
+	},
+}
+
+// execCredential is the client.authentication.k8s.io ExecCredential returned by
+// exec credential plugins.
+type execCredential struct {
+	APIVersion string                `json:"apiVersion"`
+	Kind       string                `json:"kind"`
+	Status     *execCredentialStatus `json:"status,omitempty"`
+}
+
+// execCredentialStatus holds the credentials of an ExecCredential.
+type execCredentialStatus struct {
+	ExpirationTimestamp   string `json:"expirationTimestamp,omitempty"`
+	Token                 string `json:"token,omitempty"`
+	ClientCertificateData string `json:"clientCertificateData,omitempty"`
+	ClientKeyData         string `json:"clientKeyData,omitempty"`
+}
+
+// checkSealable returns an error if the secrets of a user cannot be moved into the vault.
+func checkSealable(name string, authInfo *api.AuthInfo) error {
+	switch {
+	case authInfo.Exec != nil:
+		return fmt.Errorf("user '%s' already uses an exec plugin and cannot be sealed", name)
+	case authInfo.AuthProvider != nil:
+		return fmt.Errorf("user '%s' uses an auth provider and cannot be sealed", name)
+	case authInfo.TokenFile != "":
+		return fmt.Errorf("user '%s' references a token file; run 'kedit flatten user %s' first", name, name)
+	case authInfo.Token == "" && len(authInfo.ClientKeyData) == 0:
+		return fmt.Errorf("user '%s' has no embedded token or client key to seal", name)
+	case len(authInfo.ClientKeyData) > 0 && len(authInfo.ClientCertificateData) == 0:
+		return fmt.Errorf("user '%s' has an embedded client key but no embedded client certificate; run 'kedit flatten user %s' first", name, name)
+	case authInfo.ClientCertificate != "" || authInfo.ClientKey != "":
+		return fmt.Errorf("user '%s' also references certificate or key files; run 'kedit flatten user %s' first", name, name)
+	}
+	return nil
+}
+
+// vaultEntryName returns the name of the vault entry holding the credentials of
+// a user: the user name followed by a hash of the absolute kubeconfig path.
+func vaultEntryName(kubeconfigPath, name string) (string, error) {
+	path, err := absolutePath(kubeconfigPath)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(path))
+	return name + "@" + hex.EncodeToString(sum[:6]), nil
+}
+
+// vaultExecConfig returns the exec plugin that gets the credentials stored under name.
+func vaultExecConfig(name string) *api.ExecConfig {
+	args := []string{"credential", name}
+	if vaultPath != "" {
+		// resolveVaultPath has already validated the path.
+		path, _ := absolutePath(vaultPath)
+		args = append(args, "--vault", path)
+	}
+	return &api.ExecConfig{
+		APIVersion:      execCredentialAPIVersion,
+		Command:         vaultCommand,
+		Args:            args,
+		InteractiveMode: api.IfAvailableExecInteractiveMode,
+	}
+}
+
+// vaultReference returns the vault entry name and vault path (empty for the
+// default vault) used by an exec plugin created by vault seal.
+func vaultReference(exec *api.ExecConfig) (string, string, bool) {
+	if exec == nil || len(exec.Args) < 2 || exec.Args[0] != "credential" {
+		return "", "", false
+	}
+	path := ""
+	if len(exec.Args) == 4 && exec.Args[2] == "--vault" {
+		path = exec.Args[3]
+	}
+	return exec.Args[1], path, true
+}
+
+// resolveVaultPath returns the --vault flag, or the default vault in the kedit config directory.
+func resolveVaultPath() (string, error) {
+	if vaultPath != "" {
+		return absolutePath(vaultPath)
+	}
+	settingsPath, err := keditSettingsPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(settingsPath), "vault.json"), nil
+}
+
+// loadVault decrypts the vault file. A missing file is an empty vault.
+func loadVault(path string, passphrase []byte) (*credentialVault, error) {
+	vault := &credentialVault{Entries: make(map[string]vaultEntry)}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return vault, nil
+		}
+		return nil, fmt.Errorf("failed to read vault '%s': %w", path, err)
+	}
+	var envelope passphraseEnvelope
+	if err := json.Unmarshal(content, &envelope); err != nil {
+		return nil, fmt.Errorf("failed to parse vault '%s': %w", path, err)
+	}
+	plaintext, err := envelope.open(passphrase, vaultPurpose)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open vault '%s': %w", path, err)
+	}
+	if err := json.Unmarshal(plaintext, vault); err != nil {
+		return nil, fmt.Errorf("failed to parse vault '%s': %w", path, err)
+	}
+	if vault.Entries == nil {
+		vault.Entries = make(map[string]vaultEntry)
+	}
+	return vault, nil
+}
+
+// restoreVault puts back the vault file content read before it was saved, or
+// removes the file if it did not exist.
+func restoreVault(path string, previous []byte, existed bool) error {
+	if !existed {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove vault '%s': %w", path, err)
+		}
+		return nil
+	}
+	if err := os.WriteFile(path, previous, 0600); err != nil {
+		return fmt.Errorf("failed to restore vault '%s': %w", path, err)
+	}
+	return nil
+}
+
+// saveVault encrypts the vault and writes it with owner-only permissions.
+func saveVault(path string, vault *credentialVault, passphrase []byte) error {
+	plaintext, err := json.Marshal(vault)
+	if err != nil {
+		return fmt.Errorf("failed to serialize vault: %w", err)
+	}
+	envelope, err := sealWithPassphrase(plaintext, passphrase, vaultPurpose)
+	if err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(envelope, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize vault: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create directory '%s': %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		return fmt.Errorf("failed to write vault '%s': %w", path, err)
+	}
+	return nil
+}
+
+func init() {
+	vaultCmd.PersistentFlags().StringVar(&vaultPath, "vault", "", "Path of the vault file (default: in the kedit config directory)")
+	vaultSealCmd.Flags().StringVar(&vaultCommand, "command", "kedit", "Command the exec plugin runs, looked up in PATH unless it is a path")
+	credentialCmd.Flags().StringVar(&vaultPath, "vault", "", "Path of the vault file (default: in the kedit config directory)")
+	vaultCmd.AddCommand(vaultSealCmd)
+	vaultCmd.AddCommand(vaultUnsealCmd)
+	rootCmd.AddCommand(vaultCmd)
+	rootCmd.AddCommand(credentialCmd)
+}
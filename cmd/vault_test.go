@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVaultCommands(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "kedit-test-vault-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+	t.Setenv("KEDIT_CONFIG", filepath.Join(tempDir, "kedit", "config.yaml"))
+	t.Setenv("KEDIT_VAULT_PASSPHRASE", "correct horse")
+	vaultFile := filepath.Join(tempDir, "kedit", "vault.json")
+
+	kubeconfigPath := filepath.Join(tempDir, "config")
+	err = ioutil.WriteFile(kubeconfigPath, []byte(`
+apiVersion: v1
+clusters:
+- cluster:
+    server: https://cluster1
+  name: cluster1
+contexts:
+- context:
+    cluster: cluster1
+    user: user1
+  name: context1
+current-context: context1
+kind: Config
+preferences: {}
+users:
+- name: user1
+  user:
+    token: secret-token
+- name: user2
+  user:
+    client-certificate-data: Y2VydA==
+    client-key-data: a2V5
+- name: user3
+  user:
+    client-certificate: user3.crt
+    client-key: user3.key
+- name: user4
+  user:
+    tokenFile: user4.token
+`), 0644)
+	assert.NoError(t, err)
+	user1Key, err := vaultEntryName(kubeconfigPath, "user1")
+	assert.NoError(t, err)
+	user2Key, err := vaultEntryName(kubeconfigPath, "user2")
+	assert.NoError(t, err)
+
+	// Test that sealing moves the secrets into the vault and adds the exec plugin.
+	t.Run("vault seal", func(t *testing.T) {
+		output := executeCommandC(t, "vault", "seal", "user1", "user2", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Sealed the credentials of user 'user1' into '"+vaultFile+"'.")
+		assert.Contains(t, output, "Sealed the credentials of user 'user2' into '"+vaultFile+"'.")
+
+		content, err := ioutil.ReadFile(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.NotContains(t, string(content), "secret-token")
+		assert.NotContains(t, string(content), "a2V5")
+
+		config, err := loadKubeconfig(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.Equal(t, "kedit", config.AuthInfos["user1"].Exec.Command)
+		assert.Equal(t, []string{"credential", user1Key}, config.AuthInfos["user1"].Exec.Args)
+		assert.Equal(t, "client.authentication.k8s.io/v1", config.AuthInfos["user1"].Exec.APIVersion)
+
+		vaultContent, err := ioutil.ReadFile(vaultFile)
+		assert.NoError(t, err)
+		assert.NotContains(t, string(vaultContent), "secret-token")
+		info, err := os.Stat(vaultFile)
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+		output = executeCommandC(t, "vault", "seal", "user3", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Error: user 'user3' has no embedded token or client key to seal")
+		output = executeCommandC(t, "vault", "seal", "user1", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Error: user 'user1' already uses an exec plugin and cannot be sealed")
+		output = executeCommandC(t, "vault", "seal", "user4", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Error: user 'user4' references a token file; run 'kedit flatten user user4' first")
+	})
+
+	// Test that the credential plugin prints an ExecCredential.
+	t.Run("vault credential", func(t *testing.T) {
+		output := executeCommandC(t, "credential", user2Key)
+		var credential execCredential
+		assert.NoError(t, json.Unmarshal([]byte(output), &credential))
+		assert.Equal(t, "ExecCredential", credential.Kind)
+		assert.Equal(t, "cert", credential.Status.ClientCertificateData)
+		assert.Equal(t, "key", credential.Status.ClientKeyData)
+
+		output = executeCommandC(t, "credential", user1Key)
+		assert.Equal(t, `{"apiVersion":"client.authentication.k8s.io/v1","kind":"ExecCredential","status":{"token":"secret-token"}}`, output)
+
+		t.Setenv("KEDIT_VAULT_PASSPHRASE", "wrong")
+		output = executeCommandC(t, "credential", user1Key)
+		assert.Contains(t, output, "Error: failed to open vault '"+vaultFile+"': wrong passphrase or corrupted data")
+	})
+
+	// Test that unsealing restores the secrets and empties the vault.
+	t.Run("vault unseal", func(t *testing.T) {
+		output := executeCommandC(t, "vault", "unseal", "user1", "user2", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Unsealed the credentials of user 'user1' from '"+vaultFile+"'.")
+
+		config, err := loadKubeconfig(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.Nil(t, config.AuthInfos["user1"].Exec)
+		assert.Equal(t, "secret-token", config.AuthInfos["user1"].Token)
+		assert.Equal(t, []byte("key"), config.AuthInfos["user2"].ClientKeyData)
+
+		output = executeCommandC(t, "credential", user1Key)
+		assert.Contains(t, output, "Error: the vault '"+vaultFile+"' contains no credentials named '"+user1Key+"'")
+		output = executeCommandC(t, "vault", "unseal", "user1", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "Error: user 'user1' is not sealed")
+	})
+
+	// Test that --command writes an explicit plugin command.
+	t.Run("vault seal command", func(t *testing.T) {
+		executeCommandC(t, "vault", "seal", "user1", "--command", "/opt/kedit/bin/kedit", "--kubeconfig", kubeconfigPath)
+		config, err := loadKubeconfig(kubeconfigPath)
+		assert.NoError(t, err)
+		assert.Equal(t, "/opt/kedit/bin/kedit", config.AuthInfos["user1"].Exec.Command)
+		assert.Equal(t, []string{"credential", user1Key}, config.AuthInfos["user1"].Exec.Args)
+	})
+
+	// Test that users of the same name in different kubeconfigs share the vault.
+	t.Run("vault seal same user name", func(t *testing.T) {
+		otherPath := filepath.Join(tempDir, "other")
+		err := ioutil.WriteFile(otherPath, []byte(`
+apiVersion: v1
+kind: Config
+users:
+- name: user1
+  user:
+    token: other-token
+`), 0644)
+		assert.NoError(t, err)
+		otherKey, err := vaultEntryName(otherPath, "user1")
+		assert.NoError(t, err)
+		assert.NotEqual(t, user1Key, otherKey)
+
+		output := executeCommandC(t, "vault", "seal", "user1", "--kubeconfig", otherPath)
+		assert.Contains(t, output, "Sealed the credentials of user 'user1' into '"+vaultFile+"'.")
+		output = executeCommandC(t, "credential", otherKey)
+		assert.Contains(t, output, `"token":"other-token"`)
+		output = executeCommandC(t, "credential", user1Key)
+		assert.Contains(t, output, `"token":"secret-token"`)
+	})
+}
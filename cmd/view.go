@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	viewMinify  bool   // Flag to only show the current (or --context) context
+	viewContext string // Flag for the context to show with --minify
+	viewRedact  bool   // Flag to replace secrets with markers
+	viewRaw     bool   // Flag to show secrets and embedded data as they are
+	viewOutput  string // Flag for the output format
+	viewMerged  bool   // Flag to show the merged $KUBECONFIG chain
+)
+
+// viewCmd represents the view command
+var viewCmd = &cobra.Command{
+	Use:   "view [--minify] [--context <context_name>] [--raw] [-o yaml|json] [--merged]",
+	Short: "Print the effective kubeconfig, with secrets redacted",
+	Long: `Print the target kubeconfig, so that it can be safely shared, e.g. in a ticket.
+
+By default secrets are redacted: tokens, passwords, keys, auth-provider
+settings and exec environment values are replaced with REDACTED, and embedded
+certificate data with DATA+OMITTED. --raw prints them as they are.
+
+--minify only shows the current context and the cluster and user it
+references; --context selects another context and implies --minify.
+--merged shows the files in $KUBECONFIG merged the way kubectl does, instead
+of the target kubeconfig alone (unless --kubeconfig is given).
+--output (or -o) selects the format: yaml (the default) or json.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if viewOutput != "yaml" && viewOutput != "json" {
+			return fmt.Errorf("invalid output format '%s'. Must be one of: yaml, json", viewOutput)
+		}
+		if viewRaw && cmd.Flags().Changed("redact") && viewRedact {
+			return fmt.Errorf("flags --raw and --redact cannot be used together")
+		}
+
+		var config *api.Config
+		var err error
+		source := "'" + resolvedKubeconfigPath + "'"
+		if viewMerged {
+			paths := kubeconfigChainPaths()
+			if config, err = loadKubeconfigChain(paths); err != nil {
+				return fmt.Errorf("error loading kubeconfig chain: %w", err)
+			}
+			source = "'" + strings.Join(paths, string(filepath.ListSeparator)) + "'"
+		} else if config, err = loadKubeconfig(resolvedKubeconfigPath); err != nil {
+			return fmt.Errorf("error loading kubeconfig from '%s': %w", resolvedKubeconfigPath, err)
+		}
+
+		if viewMinify || viewContext != "" {
+			if config, err = minifyView(config, viewContext, source); err != nil {
+				return err
+			}
+		}
+		if viewRedact && !viewRaw {
+			if config, err = redactConfig(config); err != nil {
+				return err
+			}
+		}
+
+		content, err := clientcmd.Write(*config)
+		if err != nil {
+			return fmt.Errorf("failed to serialize kubeconfig: %w", err)
+		}
+		if viewOutput == "json" {
+			if content, err = yamlToIndentedJSON(content); err != nil {
+				return err
+			}
+		}
+		fmt.Print(string(content))
+		return nil
+	},
+}
+
+// minifyView returns a copy of config reduced to the given context, or the
+// current context if contextName is empty, and the cluster and user it references.
+func minifyView(config *api.Config, contextName, source string) (*api.Config, error) {
+	if contextName == "" {
+		contextName = config.CurrentContext
+		if contextName == "" {
+			return nil, fmt.Errorf("no current context set in %s; use --context to choose one", source)
+		}
+	}
+	if _, ok := config.Contexts[contextName]; !ok {
+		return nil, fmt.Errorf("context '%s' not found in %s", contextName, source)
+	}
+	minified := config.DeepCopy()
+	minified.CurrentContext = contextName
+	if err := api.MinifyConfig(minified); err != nil {
+		return nil, fmt.Errorf("failed to minify kubeconfig: %w", err)
+	}
+	return minified, nil
+}
+
+// yamlToIndentedJSON converts a YAML document to indented JSON.
+func yamlToIndentedJSON(content []byte) ([]byte, error) {
+	jsonContent, err := yaml.YAMLToJSON(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert kubeconfig to JSON: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, jsonContent, "", "  "); err != nil {
+		return nil, fmt.Errorf("failed to convert kubeconfig to JSON: %w", err)
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// loadKubeconfigChain loads and merges the given kubeconfig files the way
+// kubectl does: the first current-context and the first definition of each
+// entry win, and relative file references are resolved against the file they
+// appear in. Unlike clientcmd's loading rules, it opens encrypted kubeconfigs
+// and sealed fields.
+func loadKubeconfigChain(paths []string) (*api.Config, error) {
+	merged := api.NewConfig()
+	for _, path := range paths {
+		config, err := loadKubeconfig(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := clientcmd.ResolveLocalPaths(config); err != nil {
+			return nil, fmt.Errorf("error resolving file references in '%s': %w", path, err)
+		}
+		if merged.CurrentContext == "" {
+			merged.CurrentContext = config.CurrentContext
+		}
+		mergeMissing(merged.Clusters, config.Clusters)
+		mergeMissing(merged.AuthInfos, config.AuthInfos)
+		mergeMissing(merged.Contexts, config.Contexts)
+		mergeMissing(merged.Extensions, config.Extensions)
+	}
+	return merged, nil
+}
+
+// mergeMissing adds the entries of from that are not yet in into.
+func mergeMissing[V any](into, from map[string]V) {
+	for name, entry := range from {
+		if _, ok := into[name]; !ok {
+			into[name] = entry
+		}
+	}
+}
+
+func init() {
+	viewCmd.Flags().BoolVar(&viewMinify, "minify", false, "Only show the current context and the cluster and user it references")
+	viewCmd.Flags().StringVar(&viewContext, "context", "", "Context to show (implies --minify)")
+	viewCmd.Flags().BoolVar(&viewRedact, "redact", true, "Replace secrets and embedded data with markers")
+	viewCmd.Flags().BoolVar(&viewRaw, "raw", false, "Show secrets and embedded data as they are")
+	viewCmd.Flags().StringVarP(&viewOutput, "output", "o", "yaml", "Output format: yaml or json")
+	viewCmd.Flags().BoolVar(&viewMerged, "merged", false, "Show the files in $KUBECONFIG merged, as kubectl does")
+	viewCmd.RegisterFlagCompletionFunc("context", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return completeTargetNames("context", toComplete, nil)
+	})
+	viewCmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"yaml", "json"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	rootCmd.AddCommand(viewCmd)
+}
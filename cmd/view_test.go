@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestViewCommand(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "kedit-test-view-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	kubeconfigPath := filepath.Join(tempDir, "config")
+	err = ioutil.WriteFile(kubeconfigPath, []byte(`
+apiVersion: v1
+clusters:
+- cluster:
+    server: https://cluster1
+    certificate-authority-data: Y2EtZGF0YQ==
+  name: cluster1
+- cluster:
+    server: https://cluster2
+  name: cluster2
+contexts:
+- context:
+    cluster: cluster1
+    user: user1
+  name: context1
+- context:
+    cluster: cluster2
+    user: user2
+  name: context2
+current-context: context1
+kind: Config
+preferences: {}
+users:
+- name: user1
+  user:
+    token: secret-token
+- name: user2
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1
+      command: get-token
+      env:
+      - name: API_KEY
+        value: secret-key
+`), 0644)
+	assert.NoError(t, err)
+
+	// Test that secrets are redacted by default and shown with --raw.
+	t.Run("view redact and raw", func(t *testing.T) {
+		output := executeCommandC(t, "view", "--kubeconfig", kubeconfigPath)
+		assert.Contains(t, output, "token: REDACTED")
+		assert.Contains(t, output, "value: REDACTED")
+		assert.Contains(t, output, "certificate-authority-data: DATA+OMITTED")
+		assert.Contains(t, output, "name: context2")
+		assert.NotContains(t, output, "secret-")
+
+		output = executeCommandC(t, "view", "--kubeconfig", kubeconfigPath, "--raw")
+		assert.Contains(t, output, "token: secret-token")
+		assert.Contains(t, output, "certificate-authority-data: Y2EtZGF0YQ==")
+	})
+
+	// Test that --minify and --context only show one context.
+	t.Run("view minify", func(t *testing.T) {
+		output := executeCommandC(t, "view", "--kubeconfig", kubeconfigPath, "--minify")
+		assert.Contains(t, output, "name: context1")
+		assert.NotContains(t, output, "cluster2")
+		assert.NotContains(t, output, "user2")
+
+		output = executeCommandC(t, "view", "--kubeconfig", kubeconfigPath, "--context", "context2", "-o", "json")
+		assert.Contains(t, output, `"current-context": "context2"`)
+		assert.Contains(t, output, `"command": "get-token"`)
+		assert.NotContains(t, output, "cluster1")
+
+		output = executeCommandC(t, "view", "--kubeconfig", kubeconfigPath, "--context", "missing")
+		assert.Contains(t, output, "Error: context 'missing' not found in '"+kubeconfigPath+"'")
+	})
+
+	// Test that --merged combines the files in $KUBECONFIG.
+	t.Run("view merged", func(t *testing.T) {
+		otherPath := filepath.Join(tempDir, "other")
+		err := ioutil.WriteFile(otherPath, []byte(`
+apiVersion: v1
+clusters:
+- cluster:
+    server: https://cluster3
+  name: cluster3
+contexts:
+- context:
+    cluster: cluster3
+  name: context3
+current-context: context3
+kind: Config
+`), 0644)
+		assert.NoError(t, err)
+		t.Setenv("KUBECONFIG", kubeconfigPath+string(filepath.ListSeparator)+otherPath)
+
+		output := executeCommandC(t, "view", "--merged")
+		assert.Contains(t, output, "current-context: context1")
+		assert.Contains(t, output, "name: context2")
+		assert.Contains(t, output, "name: context3")
+
+		// Encrypted files in the chain are decrypted.
+		t.Setenv("KEDIT_PASSPHRASE", "view passphrase")
+		content, err := ioutil.ReadFile(otherPath)
+		assert.NoError(t, err)
+		encrypted, err := encryptKubeconfig(content, []byte("view passphrase"))
+		assert.NoError(t, err)
+		assert.NoError(t, ioutil.WriteFile(otherPath, encrypted, 0600))
+		output = executeCommandC(t, "view", "--merged")
+		assert.Contains(t, output, "name: context3")
+		assert.Contains(t, output, "server: https://cluster3")
+	})
+}